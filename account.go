@@ -0,0 +1,54 @@
+package bittrex
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountV3 identifies the account of the API key. SubaccountID is set for a subaccount;
+// ActionsNeeded lists what the account holder must do before it can trade, if anything.
+type AccountV3 struct {
+	SubaccountID  string   `json:"subaccountId"`
+	AccountID     string   `json:"accountId"`
+	ActionsNeeded []string `json:"actionsNeeded"`
+}
+
+// AccountVolumeV3 is the trading volume of the account over the last 30 days, in USD,
+// from which the fee tier derives.
+type AccountVolumeV3 struct {
+	Updated      time.Time       `json:"updated"`
+	Volume30days decimal.Decimal `json:"volume30days"`
+}
+
+// TradingFeeV3 is the commission rate of the account on a market, charged on the quote
+// amount of fills: MakerRate for orders resting on the book, TakerRate for those matching it.
+type TradingFeeV3 struct {
+	MarketSymbol string          `json:"marketSymbol"`
+	MakerRate    decimal.Decimal `json:"makerRate"`
+	TakerRate    decimal.Decimal `json:"takerRate"`
+}
+
+// MarketPermissionV3 tells whether the account may view, buy and sell on a market.
+type MarketPermissionV3 struct {
+	Symbol string `json:"symbol"`
+	View   bool   `json:"view"`
+	Buy    bool   `json:"buy"`
+	Sell   bool   `json:"sell"`
+}
+
+// FundingPermissions tells which funding methods the account may use for a currency.
+type FundingPermissions struct {
+	Blockchain   bool `json:"blockchain"`
+	CreditCard   bool `json:"creditCard"`
+	WireTransfer bool `json:"wireTransfer"`
+	ACH          bool `json:"ach"`
+}
+
+// CurrencyPermissionV3 tells whether the account may view, deposit and withdraw a currency.
+type CurrencyPermissionV3 struct {
+	Symbol   string             `json:"symbol"`
+	View     bool               `json:"view"`
+	Deposit  FundingPermissions `json:"deposit"`
+	Withdraw FundingPermissions `json:"withdraw"`
+}
@@ -0,0 +1,48 @@
+package bittrex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetCommissionRate(d("0.001"))
+	srv.SetAccountVolume(d("125000"))
+
+	account, err := b.GetAccount()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, account.AccountID)
+	assert.Empty(t, account.SubaccountID)
+
+	volume, err := b.GetAccountVolume()
+	assert.Nil(t, err)
+	assert.True(t, d("125000").Equal(volume.Volume30days))
+
+	fees, err := b.GetTradingFees()
+	assert.Nil(t, err)
+	assert.Len(t, fees, 1)
+	fee, err := b.GetTradingFee("ltc-btc")
+	assert.Nil(t, err)
+	assert.Equal(t, "LTC-BTC", fee.MarketSymbol)
+	assert.True(t, d("0.001").Equal(fee.MakerRate))
+	assert.True(t, d("0.001").Equal(fee.TakerRate))
+	_, err = b.GetTradingFee("DOGE-BTC")
+	assert.True(t, IsNotFound(err))
+
+	markets, err := b.GetMarketPermissions("LTC-BTC")
+	assert.Nil(t, err)
+	if assert.Len(t, markets, 1) {
+		assert.True(t, markets[0].Buy && markets[0].Sell)
+	}
+	currencies, err := b.GetCurrencyPermissions("all")
+	assert.Nil(t, err)
+	assert.Len(t, currencies, 2)
+
+	sub, err := b.CreateSubaccount()
+	assert.Nil(t, err)
+	account, err = b.WithSubaccount(sub.ID).GetAccount()
+	assert.Nil(t, err)
+	assert.Equal(t, sub.ID, account.SubaccountID)
+}
@@ -0,0 +1,113 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BatchOperation is an operation of a batch request, built by BatchCreateOrder or BatchCancelOrder.
+type BatchOperation struct {
+	Resource  string      `json:"resource"`
+	Operation string      `json:"operation"`
+	Payload   interface{} `json:"payload"`
+}
+
+// BatchCreateOrder returns the batch operation creating an order.
+func BatchCreateOrder(params CreateOrderParams) BatchOperation {
+	return BatchOperation{Resource: "order", Operation: "post", Payload: params}
+}
+
+// BatchCancelOrder returns the batch operation cancelling an order.
+func BatchCancelOrder(orderID string) BatchOperation {
+	return BatchOperation{Resource: "order", Operation: "delete", Payload: OrderData{ID: orderID}}
+}
+
+// BatchResult is the result of one operation of a batch: the order created or cancelled,
+// or an *APIError.
+type BatchResult struct {
+	Order OrderV3
+	Err   error
+}
+
+// batchPayload checks the operations and keeps the fields relevant to the type of the orders created.
+func (b *Bittrex) batchPayload(ctx context.Context, operations []BatchOperation) ([]BatchOperation, error) {
+	final := make([]BatchOperation, len(operations))
+	for i, op := range operations {
+		switch params := op.Payload.(type) {
+		case CreateOrderParams:
+			order, err := b.orderPayload(ctx, params)
+			if err != nil {
+				return nil, fmt.Errorf("batch operation %d: %w", i, err)
+			}
+			op.Payload = order
+		case OrderData:
+			if params.ID == "" {
+				return nil, fmt.Errorf("batch operation %d: %w: order id is required", i, ERR_ORDER_INVALID_PARAMETERS)
+			}
+			op.Payload = struct {
+				ID string `json:"id"`
+			}{params.ID}
+		default:
+			return nil, fmt.Errorf("batch operation %d: %w: build operations with BatchCreateOrder or BatchCancelOrder", i, ERR_ORDER_INVALID_PARAMETERS)
+		}
+		final[i] = op
+	}
+	return final, nil
+}
+
+// batchResults decodes the response of POST /batch, whose items are an order or a v3 error.
+// Method and URL of the errors are those of the batch request.
+func batchResults(r []byte, method, url string) (results []BatchResult, err error) {
+	var items []struct {
+		Status  int             `json:"status"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err = json.Unmarshal(r, &items); err != nil {
+		return
+	}
+	results = make([]BatchResult, len(items))
+	for i, item := range items {
+		if item.Status == http.StatusOK || item.Status == http.StatusCreated {
+			err = json.Unmarshal(item.Payload, &results[i].Order)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		e := &APIError{
+			StatusCode: item.Status,
+			Status:     fmt.Sprintf("%d %s", item.Status, http.StatusText(item.Status)),
+			Method:     method,
+			URL:        url,
+			Body:       item.Payload,
+		}
+		e.decode(item.Payload)
+		results[i].Err = e
+	}
+	return
+}
+
+// cancelResults decodes the response of DELETE /orders/open, whose items are the cancelled
+// order or the code of the error that prevented it.
+func cancelResults(r []byte, method, url string) (results []BatchResult, err error) {
+	var items []struct {
+		ID         string  `json:"id"`
+		StatusCode string  `json:"statusCode"`
+		Result     OrderV3 `json:"result"`
+	}
+	if err = json.Unmarshal(r, &items); err != nil {
+		return
+	}
+	results = make([]BatchResult, len(items))
+	for i, item := range items {
+		if item.Result.ID != "" {
+			results[i].Order = item.Result
+			continue
+		}
+		results[i].Order.ID = item.ID
+		results[i].Err = &APIError{Code: item.StatusCode, Method: method, URL: url}
+	}
+	return
+}
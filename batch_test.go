@@ -0,0 +1,106 @@
+package bittrex
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+
+	first, err := b.LimitBuy("LTC-BTC", d("1"), d("0.003"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	results, err := b.Batch([]BatchOperation{
+		BatchCancelOrder(first.ID),
+		BatchCreateOrder(CreateOrderParams{
+			MarketSymbol: "LTC-BTC",
+			Direction:    BUY,
+			Type:         LIMIT,
+			Quantity:     d("1"),
+			Limit:        d("0.0031"),
+			TimeInForce:  GOOD_TIL_CANCELLED,
+		}),
+		BatchCancelOrder("unknown"),
+		BatchCreateOrder(CreateOrderParams{
+			MarketSymbol: "LTC-BTC",
+			Direction:    BUY,
+			Type:         LIMIT,
+			Quantity:     d("1000"),
+			Limit:        d("0.0031"),
+			TimeInForce:  GOOD_TIL_CANCELLED,
+		}),
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, results, 4) {
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, first.ID, results[0].Order.ID)
+		assert.Equal(t, "CLOSED", results[0].Order.Status)
+		assert.Nil(t, results[1].Err)
+		assert.Equal(t, "OPEN", results[1].Order.Status)
+		assert.True(t, IsNotFound(results[2].Err))
+		assert.True(t, IsInsufficientFunds(results[3].Err))
+	}
+
+	_, err = b.Batch([]BatchOperation{BatchCancelOrder("")})
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+
+	_, err = b.LimitBuy("LTC-BTC", d("1"), d("0.0032"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	results, err = b.CancelAllOrders("LTC-BTC")
+	assert.Nil(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, "CLOSED", results[0].Order.Status)
+	}
+	open, err := b.GetOpenOrders("all")
+	assert.Nil(t, err)
+	assert.Empty(t, open)
+	assert.True(t, srv.Balance("BTC").Available.Equal(d("1")))
+}
+
+func TestBatchResults(t *testing.T) {
+	results, err := batchResults([]byte(`[
+		{"status": 201, "payload": {"id": "created", "status": "OPEN"}},
+		{"status": 409, "payload": {"code": "INSUFFICIENT_FUNDS"}}
+	]`), http.MethodPost, "https://api.bittrex.com/v3/batch")
+	assert.Nil(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, "created", results[0].Order.ID)
+		assert.True(t, IsInsufficientFunds(results[1].Err))
+		var apiErr *APIError
+		if assert.True(t, errors.As(results[1].Err, &apiErr)) {
+			assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+			assert.Equal(t, "409 Conflict", apiErr.Status)
+			assert.Equal(t, http.MethodPost, apiErr.Method)
+			assert.Equal(t, "https://api.bittrex.com/v3/batch", apiErr.URL)
+		}
+	}
+
+	_, err = batchResults([]byte(`[{"status": 200, "payload": "order"}]`), http.MethodPost, "")
+	assert.Error(t, err)
+	_, err = batchResults([]byte(`{}`), http.MethodPost, "")
+	assert.Error(t, err)
+}
+
+func TestCancelResults(t *testing.T) {
+	results, err := cancelResults([]byte(`[
+		{"id": "cancelled", "statusCode": "SUCCESS", "result": {"id": "cancelled", "status": "CLOSED"}},
+		{"id": "closed", "statusCode": "ORDER_NOT_OPEN"}
+	]`), http.MethodDelete, "https://api.bittrex.com/v3/orders/open")
+	assert.Nil(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Nil(t, results[0].Err)
+		assert.Equal(t, ORDER_CLOSED, results[0].Order.Status)
+		assert.Equal(t, "closed", results[1].Order.ID)
+		var apiErr *APIError
+		if assert.True(t, errors.As(results[1].Err, &apiErr)) {
+			assert.Equal(t, "ORDER_NOT_OPEN", apiErr.Code)
+			assert.Equal(t, http.MethodDelete, apiErr.Method)
+		}
+	}
+}
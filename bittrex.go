@@ -2,17 +2,17 @@
 package bittrex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math/rand"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -22,6 +22,8 @@ const (
 	WS_BASE     = "socket-v3.bittrex.com" // Bittrex WS API endpoint
 	WS_HUB      = "c3"                    // SignalR main hub
 
+	LEGACY_API_BASE = "https://bittrex.com/Api/v2.0/" // Bittrex v2.0 API endpoint
+
 	//ORDERBOOK const
 	BALANCE = "balance"
 
@@ -37,24 +39,38 @@ const (
 	HEARTBEAT = "heartbeat"
 	//AUTHEXPIRED const
 	AUTHEXPIRED = "authenticationExpiring"
+	//EXECUTION const
+	EXECUTION = "execution"
+	//CONDITIONAL_ORDER const
+	CONDITIONAL_ORDER = "conditionalOrder"
+	//MARKET_SUMMARY const
+	MARKET_SUMMARY = "marketSummary"
+	//MARKET_SUMMARIES const
+	MARKET_SUMMARIES = "marketSummaries"
+	//TICKERS const
+	TICKERS = "tickers"
 )
 
-// New returns an instantiated bittrex struct
-func New(apiKey, apiSecret string) *Bittrex {
-	client := NewClient(apiKey, apiSecret)
-	return &Bittrex{client}
+// New returns an instantiated bittrex struct, configured by opts
+func New(apiKey, apiSecret string, opts ...Option) *Bittrex {
+	client := newClient(apiKey, apiSecret, opts...)
+	b := &Bittrex{client}
+	client.refData = newReferenceData(b, client.refDataTTL)
+	return b
 }
 
 // NewWithCustomHttpClient returns an instantiated bittrex struct with custom http client
+//
+// Deprecated: use New(apiKey, apiSecret, WithHTTPClient(httpClient))
 func NewWithCustomHttpClient(apiKey, apiSecret string, httpClient *http.Client) *Bittrex {
-	client := NewClientWithCustomHttpConfig(apiKey, apiSecret, httpClient)
-	return &Bittrex{client}
+	return New(apiKey, apiSecret, WithHTTPClient(httpClient))
 }
 
 // NewWithCustomTimeout returns an instantiated bittrex struct with custom timeout
+//
+// Deprecated: use New(apiKey, apiSecret, WithTimeout(timeout))
 func NewWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) *Bittrex {
-	client := NewClientWithCustomTimeout(apiKey, apiSecret, timeout)
-	return &Bittrex{client}
+	return New(apiKey, apiSecret, WithTimeout(timeout))
 }
 
 // handleErr gets JSON response from Bittrex API en deal with error
@@ -70,14 +86,54 @@ type Bittrex struct {
 	client *client
 }
 
+// WithSubaccount returns a handle acting on behalf of a subaccount: its authenticated requests
+// carry the Api-Subaccount-Id header, which is part of their signature. It shares the
+// configuration and the rate limits of b. Websocket streams are not scoped.
+func (b *Bittrex) WithSubaccount(subaccountID string) *Bittrex {
+	c := *b.client
+	c.subaccountID = subaccountID
+	return &Bittrex{&c}
+}
+
+// SubaccountID returns the subaccount the handle acts on behalf of, empty for the master account.
+func (b *Bittrex) SubaccountID() string {
+	return b.client.subaccountID
+}
+
 // set enable/disable http request/response dump
 func (c *Bittrex) SetDebug(enable bool) {
 	c.client.debug = enable
 }
 
+// SetRetryPolicy sets the policy used to retry idempotent requests.
+// Use NoRetry to disable retries.
+func (c *Bittrex) SetRetryPolicy(policy RetryPolicy) {
+	c.client.retryPolicy = policy
+}
+
+// SetRateLimit sets the request budget of an endpoint class. A zero RateLimit disables limiting.
+func (c *Bittrex) SetRateLimit(class EndpointClass, limit RateLimit) {
+	c.client.limiter.setLimit(class, limit)
+}
+
+// SetRateLimitPolicy sets whether requests block or fail fast once a budget is exhausted.
+func (c *Bittrex) SetRateLimitPolicy(policy RateLimitPolicy) {
+	c.client.limiter.setPolicy(policy)
+}
+
+// RateLimitUsage returns the current budget usage of an endpoint class.
+func (c *Bittrex) RateLimitUsage(class EndpointClass) RateLimitUsage {
+	return c.client.limiter.usage(class)
+}
+
 // GetDistribution is used to get the distribution.
 func (b *Bittrex) GetDistribution(market string) (distribution Distribution, err error) {
-	r, err := b.client.do("GET", "https://bittrex.com/Api/v2.0/pub/currency/GetBalanceDistribution?currencyName="+strings.ToUpper(market), "", false)
+	return b.GetDistributionCtx(context.Background(), market)
+}
+
+// GetDistributionCtx is the context-aware variant of GetDistribution.
+func (b *Bittrex) GetDistributionCtx(ctx context.Context, market string) (distribution Distribution, err error) {
+	r, err := b.client.doCtx(ctx, "GET", b.client.legacyBaseURL+"pub/currency/GetBalanceDistribution?currencyName="+normalizeSymbol(market), "", false)
 	if err != nil {
 		return
 	}
@@ -97,7 +153,12 @@ func (b *Bittrex) GetDistribution(market string) (distribution Distribution, err
 
 // GetCurrencies is used to get all supported currencies at Bittrex along with other meta data.
 func (b *Bittrex) GetCurrencies() (currencies []CurrencyV3, err error) {
-	r, err := b.client.do("GET", "currencies", "", false)
+	return b.GetCurrenciesCtx(context.Background())
+}
+
+// GetCurrenciesCtx is the context-aware variant of GetCurrencies.
+func (b *Bittrex) GetCurrenciesCtx(ctx context.Context) (currencies []CurrencyV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "currencies", "", false)
 	if err != nil {
 		return
 	}
@@ -107,7 +168,12 @@ func (b *Bittrex) GetCurrencies() (currencies []CurrencyV3, err error) {
 
 // GetCurrency is used to get information of a single currency at Bittrex along with other meta data.
 func (b *Bittrex) GetCurrency(symbol string) (currencies CurrencyV3, err error) {
-	r, err := b.client.do("GET", "currencies/"+symbol, "", false)
+	return b.GetCurrencyCtx(context.Background(), symbol)
+}
+
+// GetCurrencyCtx is the context-aware variant of GetCurrency.
+func (b *Bittrex) GetCurrencyCtx(ctx context.Context, symbol string) (currencies CurrencyV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "currencies/"+normalizeSymbol(symbol), "", false)
 	if err != nil {
 		return
 	}
@@ -117,7 +183,12 @@ func (b *Bittrex) GetCurrency(symbol string) (currencies CurrencyV3, err error)
 
 // GetMarkets is used to get the open and available trading markets at Bittrex along with other meta data.
 func (b *Bittrex) GetMarkets() (markets []MarketV3, err error) {
-	r, err := b.client.do("GET", "markets", "", false)
+	return b.GetMarketsCtx(context.Background())
+}
+
+// GetMarketsCtx is the context-aware variant of GetMarkets.
+func (b *Bittrex) GetMarketsCtx(ctx context.Context) (markets []MarketV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "markets", "", false)
 	if err != nil {
 		return
 	}
@@ -127,15 +198,22 @@ func (b *Bittrex) GetMarkets() (markets []MarketV3, err error) {
 
 // GetTicker is used to get the current ticker values for a market, if none is specified, returns info for all.
 func (b *Bittrex) GetTicker(market string) (ticker []TickerV3, err error) {
-	market = strings.ToUpper(market)
+	return b.GetTickerCtx(context.Background(), market)
+}
+
+// GetTickerCtx is the context-aware variant of GetTicker.
+func (b *Bittrex) GetTickerCtx(ctx context.Context, market string) (ticker []TickerV3, err error) {
 	var endpoint string
 	if market == "" {
 		endpoint = "markets/tickers"
 	} else {
+		if market, err = b.cachedMarketSymbol(market); err != nil {
+			return
+		}
 		endpoint = "markets/" + market + "/ticker"
 	}
 
-	r, err := b.client.do("GET", endpoint, "", false)
+	r, err := b.client.doCtx(ctx, "GET", endpoint, "", false)
 	if err != nil {
 		return
 	}
@@ -155,7 +233,12 @@ func (b *Bittrex) GetTicker(market string) (ticker []TickerV3, err error) {
 
 // GetMarketSummaries is used to get the last 24 hour summary of all active exchanges
 func (b *Bittrex) GetMarketSummaries() (marketSummaries []MarketSummaryV3, err error) {
-	r, err := b.client.do("GET", "markets/summaries", "", false)
+	return b.GetMarketSummariesCtx(context.Background())
+}
+
+// GetMarketSummariesCtx is the context-aware variant of GetMarketSummaries.
+func (b *Bittrex) GetMarketSummariesCtx(ctx context.Context) (marketSummaries []MarketSummaryV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "markets/summaries", "", false)
 	if err != nil {
 		return
 	}
@@ -165,7 +248,15 @@ func (b *Bittrex) GetMarketSummaries() (marketSummaries []MarketSummaryV3, err e
 
 // GetMarketSummary is used to get the last 24 hour summary for a given market
 func (b *Bittrex) GetMarketSummary(market string) (marketSummary MarketSummaryV3, err error) {
-	r, err := b.client.do("GET", fmt.Sprintf("markets/%s/summary", strings.ToUpper(market)), "", false)
+	return b.GetMarketSummaryCtx(context.Background(), market)
+}
+
+// GetMarketSummaryCtx is the context-aware variant of GetMarketSummary.
+func (b *Bittrex) GetMarketSummaryCtx(ctx context.Context, market string) (marketSummary MarketSummaryV3, err error) {
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("markets/%s/summary", market), "", false)
 	if err != nil {
 		return
 	}
@@ -174,14 +265,22 @@ func (b *Bittrex) GetMarketSummary(market string) (marketSummary MarketSummaryV3
 }
 
 // GetOrderBook is used to get retrieve the orderbook for a given market
-// market: a string literal for the market (ex: BTC-LTC)
+// market: a string literal for the market (ex: LTC-BTC)
 // cat: buy, sell or both to identify the type of orderbook to return.
 func (b *Bittrex) GetOrderBook(market string, depth int32, cat string) (orderBook OrderBookV3, err error) {
+	return b.GetOrderBookCtx(context.Background(), market, depth, cat)
+}
+
+// GetOrderBookCtx is the context-aware variant of GetOrderBook.
+func (b *Bittrex) GetOrderBookCtx(ctx context.Context, market string, depth int32, cat string) (orderBook OrderBookV3, err error) {
 	if cat != "buy" && cat != "sell" && cat != "both" {
 		cat = "both"
 	}
 
-	r, err := b.client.do("GET", fmt.Sprintf("markets/%s/orderbook?depth=%s", strings.ToUpper(market), strconv.Itoa(int(depth))), "", false)
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("markets/%s/orderbook?depth=%s", market, strconv.Itoa(int(depth))), "", false)
 	if err != nil {
 		return
 	}
@@ -204,14 +303,22 @@ func (b *Bittrex) GetOrderBook(market string, depth int32, cat string) (orderBoo
 }
 
 // GetOrderBookBuySell is used to get retrieve the buy or sell side of an orderbook for a given market
-// market: a string literal for the market (ex: BTC-LTC)
+// market: a string literal for the market (ex: LTC-BTC)
 // cat: buy or sell to identify the type of orderbook to return.
 func (b *Bittrex) GetOrderBookBuySell(market string, depth int32, cat string) (orderb []OrderbV3, err error) {
+	return b.GetOrderBookBuySellCtx(context.Background(), market, depth, cat)
+}
+
+// GetOrderBookBuySellCtx is the context-aware variant of GetOrderBookBuySell.
+func (b *Bittrex) GetOrderBookBuySellCtx(ctx context.Context, market string, depth int32, cat string) (orderb []OrderbV3, err error) {
 	if cat != "buy" && cat != "sell" {
 		cat = "buy"
 	}
 
-	r, err := b.client.do("GET", fmt.Sprintf("markets/%s/orderbook?depth=%s", strings.ToUpper(market), strconv.Itoa(int(depth))), "", false)
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("markets/%s/orderbook?depth=%s", market, strconv.Itoa(int(depth))), "", false)
 	if err != nil {
 		return
 	}
@@ -228,10 +335,41 @@ func (b *Bittrex) GetOrderBookBuySell(market string, depth int32, cat string) (o
 	return
 }
 
+// GetOrderBookWithSequence is used to get the orderbook of a market along with its sequence number,
+// to be matched with the sequence of the websocket deltas.
+// depth: 1, 25 or 500
+func (b *Bittrex) GetOrderBookWithSequence(market string, depth int32) (orderBook OrderBookV3, sequence int, err error) {
+	return b.GetOrderBookWithSequenceCtx(context.Background(), market, depth)
+}
+
+// GetOrderBookWithSequenceCtx is the context-aware variant of GetOrderBookWithSequence.
+func (b *Bittrex) GetOrderBookWithSequenceCtx(ctx context.Context, market string, depth int32) (orderBook OrderBookV3, sequence int, err error) {
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, header, err := b.client.doHeaderCtx(ctx, "GET", fmt.Sprintf("markets/%s/orderbook?depth=%s", market, strconv.Itoa(int(depth))), "", false)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(r, &orderBook); err != nil {
+		return
+	}
+	sequence, err = strconv.Atoi(header.Get("Sequence"))
+	return
+}
+
 // GetMarketHistory is used to retrieve the latest trades that have occured for a specific market.
-// market a string literal for the market (ex: BTC-LTC)
+// market a string literal for the market (ex: LTC-BTC)
 func (b *Bittrex) GetMarketHistory(market string) (trades []TradeV3, err error) {
-	r, err := b.client.do("GET", fmt.Sprintf("markets/%s/trades", strings.ToUpper(market)), "", false)
+	return b.GetMarketHistoryCtx(context.Background(), market)
+}
+
+// GetMarketHistoryCtx is the context-aware variant of GetMarketHistory.
+func (b *Bittrex) GetMarketHistoryCtx(ctx context.Context, market string) (trades []TradeV3, err error) {
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("markets/%s/trades", market), "", false)
 	if err != nil {
 		return
 	}
@@ -242,40 +380,194 @@ func (b *Bittrex) GetMarketHistory(market string) (trades []TradeV3, err error)
 // Market
 
 // BuyLimit is used to place a limited buy order in a specific market.
+//
+// Deprecated: use LimitBuy, which returns the created order.
 func (b *Bittrex) BuyLimit(market string, quantity, rate decimal.Decimal) (uuid string, err error) {
-	r, err := b.client.do("GET", fmt.Sprintf("market/buylimit?market=%s&quantity=%s&rate=%s", market, quantity, rate), "", true)
-	if err != nil {
+	return b.BuyLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// BuyLimitCtx is the context-aware variant of BuyLimit.
+//
+// Deprecated: use LimitBuyCtx, which returns the created order.
+func (b *Bittrex) BuyLimitCtx(ctx context.Context, market string, quantity, rate decimal.Decimal) (uuid string, err error) {
+	order, err := b.LimitBuyCtx(ctx, market, quantity, rate, GOOD_TIL_CANCELLED)
+	return order.ID, err
+}
+
+// LimitBuy places a LIMIT order buying quantity of market at rate or lower.
+// timeInForce is GOOD_TIL_CANCELLED, IMMEDIATE_OR_CANCEL, FILL_OR_KILL or POST_ONLY_GOOD_TIL_CANCELLED.
+func (b *Bittrex) LimitBuy(market string, quantity, rate decimal.Decimal, timeInForce TimeInForce) (order OrderV3, err error) {
+	return b.LimitBuyCtx(context.Background(), market, quantity, rate, timeInForce)
+}
+
+// LimitBuyCtx is the context-aware variant of LimitBuy.
+func (b *Bittrex) LimitBuyCtx(ctx context.Context, market string, quantity, rate decimal.Decimal, timeInForce TimeInForce) (order OrderV3, err error) {
+	return b.limitOrder(ctx, market, BUY, quantity, rate, timeInForce)
+}
+
+// LimitSell places a LIMIT order selling quantity of market at rate or higher.
+// timeInForce is GOOD_TIL_CANCELLED, IMMEDIATE_OR_CANCEL, FILL_OR_KILL or POST_ONLY_GOOD_TIL_CANCELLED.
+func (b *Bittrex) LimitSell(market string, quantity, rate decimal.Decimal, timeInForce TimeInForce) (order OrderV3, err error) {
+	return b.LimitSellCtx(context.Background(), market, quantity, rate, timeInForce)
+}
+
+// LimitSellCtx is the context-aware variant of LimitSell.
+func (b *Bittrex) LimitSellCtx(ctx context.Context, market string, quantity, rate decimal.Decimal, timeInForce TimeInForce) (order OrderV3, err error) {
+	return b.limitOrder(ctx, market, SELL, quantity, rate, timeInForce)
+}
+
+// PostOnlyLimit places a LIMIT order that is only accepted if it rests on the book as a maker.
+func (b *Bittrex) PostOnlyLimit(market string, direction OrderDirection, quantity, rate decimal.Decimal) (order OrderV3, err error) {
+	return b.PostOnlyLimitCtx(context.Background(), market, direction, quantity, rate)
+}
+
+// PostOnlyLimitCtx is the context-aware variant of PostOnlyLimit.
+func (b *Bittrex) PostOnlyLimitCtx(ctx context.Context, market string, direction OrderDirection, quantity, rate decimal.Decimal) (order OrderV3, err error) {
+	if direction != BUY && direction != SELL {
+		return order, fmt.Errorf("%w: direction must be BUY or SELL", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	return b.limitOrder(ctx, market, direction, quantity, rate, POST_ONLY_GOOD_TIL_CANCELLED)
+}
+
+// MarketBuy places a MARKET order buying quantity of market, immediately or cancelled.
+func (b *Bittrex) MarketBuy(market string, quantity decimal.Decimal) (order OrderV3, err error) {
+	return b.MarketBuyCtx(context.Background(), market, quantity)
+}
+
+// MarketBuyCtx is the context-aware variant of MarketBuy.
+func (b *Bittrex) MarketBuyCtx(ctx context.Context, market string, quantity decimal.Decimal) (order OrderV3, err error) {
+	return b.marketOrder(ctx, market, BUY, quantity)
+}
+
+// MarketSell places a MARKET order selling quantity of market, immediately or cancelled.
+func (b *Bittrex) MarketSell(market string, quantity decimal.Decimal) (order OrderV3, err error) {
+	return b.MarketSellCtx(context.Background(), market, quantity)
+}
+
+// MarketSellCtx is the context-aware variant of MarketSell.
+func (b *Bittrex) MarketSellCtx(ctx context.Context, market string, quantity decimal.Decimal) (order OrderV3, err error) {
+	return b.marketOrder(ctx, market, SELL, quantity)
+}
+
+// CeilingMarketBuy places a CEILING_MARKET order spending up to ceiling of the quote currency
+// of market, e.g. 0.1 BTC on LTC-BTC, immediately or cancelled.
+func (b *Bittrex) CeilingMarketBuy(market string, ceiling decimal.Decimal) (order OrderV3, err error) {
+	return b.CeilingMarketBuyCtx(context.Background(), market, ceiling)
+}
+
+// CeilingMarketBuyCtx is the context-aware variant of CeilingMarketBuy.
+func (b *Bittrex) CeilingMarketBuyCtx(ctx context.Context, market string, ceiling decimal.Decimal) (order OrderV3, err error) {
+	if err = validateOrder(market, ceiling, "ceiling"); err != nil {
 		return
 	}
-	var response jsonResponse
-	if err = json.Unmarshal(r, &response); err != nil {
+	return b.CreateOrderCtx(ctx, CreateOrderParams{
+		MarketSymbol: market,
+		Direction:    BUY,
+		Type:         CEILING_MARKET,
+		Ceiling:      ceiling,
+		TimeInForce:  IMMEDIATE_OR_CANCEL,
+	})
+}
+
+func (b *Bittrex) limitOrder(ctx context.Context, market string, direction OrderDirection, quantity, rate decimal.Decimal, timeInForce TimeInForce) (order OrderV3, err error) {
+	if err = validateOrder(market, quantity, "quantity"); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
+	if !rate.IsPositive() {
+		return order, fmt.Errorf("%w: rate must be positive, got %s", ERR_ORDER_INVALID_PARAMETERS, rate)
+	}
+	switch timeInForce {
+	case GOOD_TIL_CANCELLED, IMMEDIATE_OR_CANCEL, FILL_OR_KILL, POST_ONLY_GOOD_TIL_CANCELLED:
+	default:
+		return order, fmt.Errorf("%w: time in force %q is not valid for LIMIT orders", ERR_ORDER_INVALID_PARAMETERS, timeInForce)
+	}
+	return b.CreateOrderCtx(ctx, CreateOrderParams{
+		MarketSymbol: market,
+		Direction:    direction,
+		Type:         LIMIT,
+		Quantity:     quantity,
+		Limit:        rate,
+		TimeInForce:  timeInForce,
+	})
+}
+
+func (b *Bittrex) marketOrder(ctx context.Context, market string, direction OrderDirection, quantity decimal.Decimal) (order OrderV3, err error) {
+	if err = validateOrder(market, quantity, "quantity"); err != nil {
 		return
 	}
-	var u Uuid
-	err = json.Unmarshal(response.Result, &u)
-	uuid = u.Id
-	return
+	return b.CreateOrderCtx(ctx, CreateOrderParams{
+		MarketSymbol: market,
+		Direction:    direction,
+		Type:         MARKET,
+		Quantity:     quantity,
+		TimeInForce:  IMMEDIATE_OR_CANCEL,
+	})
+}
+
+// validateOrder checks market is set and amount, named name in errors, is positive.
+func validateOrder(market string, amount decimal.Decimal, name string) error {
+	if market == "" {
+		return fmt.Errorf("%w: market is required", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if !amount.IsPositive() {
+		return fmt.Errorf("%w: %s must be positive, got %s", ERR_ORDER_INVALID_PARAMETERS, name, amount)
+	}
+	return nil
 }
 
 // CreateOrder is used to create any type of supported order.
 func (b *Bittrex) CreateOrder(params CreateOrderParams) (order OrderV3, err error) {
+	return b.CreateOrderCtx(context.Background(), params)
+}
+
+// CreateOrderCtx is the context-aware variant of CreateOrder.
+func (b *Bittrex) CreateOrderCtx(ctx context.Context, params CreateOrderParams) (order OrderV3, err error) {
+	finalParams, err := b.orderPayload(ctx, params)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(finalParams)
+	if err != nil {
+		return
+	}
+	// A client order id makes the creation idempotent, so it is safe to retry
+	r, _, err := b.client.doRetry(ctx, "POST", "orders", string(payload), true, params.ClientOrderID != "")
+
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(r, &order)
+	return
+}
+
+// orderPayload checks params, with the validator set by SetOrderValidator if any, and keeps
+// the fields relevant to the order type.
+func (b *Bittrex) orderPayload(ctx context.Context, params CreateOrderParams) (finalParams CreateOrderParams, err error) {
+	if v := b.client.validator; v != nil {
+		if params, err = v.Validate(ctx, params); err != nil {
+			return CreateOrderParams{}, err
+		}
+	}
 
 	// TODO Preprocessor
 	if params.Type == "" || params.MarketSymbol == "" || params.Direction == "" || params.TimeInForce == "" {
 		// Check for missing parameters
-		return OrderV3{}, ERR_ORDER_MISSING_PARAMETERS
+		return CreateOrderParams{}, ERR_ORDER_MISSING_PARAMETERS
 	}
 
 	// Mandatory fields
-	var finalParams CreateOrderParams
 	finalParams.Type = params.Type
-	finalParams.MarketSymbol = params.MarketSymbol
+	if finalParams.MarketSymbol, err = b.marketSymbol(ctx, params.MarketSymbol); err != nil {
+		return CreateOrderParams{}, err
+	}
 	finalParams.Direction = params.Direction
 	finalParams.TimeInForce = params.TimeInForce
 
+	// Optional fields
+	finalParams.ClientOrderID = params.ClientOrderID
+	finalParams.UseAwards = params.UseAwards
+
 	// Per-type fields
 	switch params.Type {
 	case MARKET:
@@ -285,27 +577,68 @@ func (b *Bittrex) CreateOrder(params CreateOrderParams) (order OrderV3, err erro
 		finalParams.Quantity = params.Quantity
 	case CEILING_LIMIT:
 		finalParams.Ceiling = params.Ceiling
+		finalParams.Limit = params.Limit
 	case CEILING_MARKET:
 		finalParams.Ceiling = params.Ceiling
 	}
+	return
+}
 
-	payload, err := json.Marshal(finalParams)
+// Batch submits order creations and cancellations, built with BatchCreateOrder and
+// BatchCancelOrder, in one request. The results are in the order of operations, each with
+// the order created or cancelled, or the error of the operation. err is only set when the
+// whole batch fails.
+func (b *Bittrex) Batch(operations []BatchOperation) (results []BatchResult, err error) {
+	return b.BatchCtx(context.Background(), operations)
+}
+
+// BatchCtx is the context-aware variant of Batch.
+func (b *Bittrex) BatchCtx(ctx context.Context, operations []BatchOperation) (results []BatchResult, err error) {
+	final, err := b.batchPayload(ctx, operations)
 	if err != nil {
 		return
 	}
-	r, err := b.client.do("POST", fmt.Sprintf("orders"), string(payload), true)
-
+	payload, err := json.Marshal(final)
 	if err != nil {
 		return
 	}
+	r, err := b.client.doCtx(ctx, "POST", "batch", string(payload), true)
+	if err != nil {
+		return
+	}
+	return batchResults(r, "POST", b.client.baseURL+"batch")
+}
 
-	err = json.Unmarshal(r, &order)
-	return
+// CancelAllOrders cancels every open order of market, or of every market if market is "all".
+// Results hold the cancelled orders, or the error that prevented their cancellation.
+func (b *Bittrex) CancelAllOrders(market string) (results []BatchResult, err error) {
+	return b.CancelAllOrdersCtx(context.Background(), market)
+}
+
+// CancelAllOrdersCtx is the context-aware variant of CancelAllOrders.
+func (b *Bittrex) CancelAllOrdersCtx(ctx context.Context, market string) (results []BatchResult, err error) {
+	resource := "orders/open"
+	if market != "" && market != "all" {
+		if market, err = b.marketSymbol(ctx, market); err != nil {
+			return
+		}
+		resource += "?marketSymbol=" + market
+	}
+	r, err := b.client.doCtx(ctx, "DELETE", resource, "", true)
+	if err != nil {
+		return
+	}
+	return cancelResults(r, "DELETE", b.client.baseURL+resource)
 }
 
 // CancelOrder is used to cancel a buy or sell order.
 func (b *Bittrex) CancelOrder(orderID string) (order OrderV3, err error) {
-	r, err := b.client.do("DELETE", "orders/"+orderID, "", true)
+	return b.CancelOrderCtx(context.Background(), orderID)
+}
+
+// CancelOrderCtx is the context-aware variant of CancelOrder.
+func (b *Bittrex) CancelOrderCtx(ctx context.Context, orderID string) (order OrderV3, err error) {
+	r, err := b.client.doCtx(ctx, "DELETE", "orders/"+orderID, "", true)
 	if err != nil {
 		return
 	}
@@ -313,18 +646,42 @@ func (b *Bittrex) CancelOrder(orderID string) (order OrderV3, err error) {
 	return
 }
 
-// GetClosedOrders returns orders that you currently have opened.
+// GetClosedOrders returns your most recent closed orders, a page of 100 at most.
 // If market is set to "all", GetClosedOrders return all orders
 // If market is set to a specific order, GetClosedOrders return orders for this market
+// ClosedOrdersIter walks the whole history.
 func (b *Bittrex) GetClosedOrders(market string) (closedOrders []OrderV3, err error) {
-	resource := "orders/closed"
-	if market == "" {
-		market = "all"
-	}
+	return b.GetClosedOrdersCtx(context.Background(), market)
+}
+
+// GetClosedOrdersCtx is the context-aware variant of GetClosedOrders.
+func (b *Bittrex) GetClosedOrdersCtx(ctx context.Context, market string) (closedOrders []OrderV3, err error) {
+	var params ClosedOrdersParams
 	if market != "all" {
-		resource += "?marketSymbol=" + strings.ToUpper(market)
+		params.MarketSymbol = market
 	}
-	r, err := b.client.do("GET", resource, "", true)
+	return b.GetClosedOrdersPageCtx(ctx, params)
+}
+
+// GetClosedOrdersPage returns a page of closed orders.
+func (b *Bittrex) GetClosedOrdersPage(params ClosedOrdersParams) (closedOrders []OrderV3, err error) {
+	return b.GetClosedOrdersPageCtx(context.Background(), params)
+}
+
+// GetClosedOrdersPageCtx is the context-aware variant of GetClosedOrdersPage.
+func (b *Bittrex) GetClosedOrdersPageCtx(ctx context.Context, params ClosedOrdersParams) (closedOrders []OrderV3, err error) {
+	if params.MarketSymbol != "" {
+		if params.MarketSymbol, err = b.cachedMarketSymbol(params.MarketSymbol); err != nil {
+			return
+		}
+	}
+	v, _ := query.Values(params)
+	queryParams := v.Encode()
+	resource := "orders/closed"
+	if len(queryParams) != 0 {
+		resource += "?"
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
 	if err != nil {
 		return
 	}
@@ -336,14 +693,22 @@ func (b *Bittrex) GetClosedOrders(market string) (closedOrders []OrderV3, err er
 // If market is set to "all", GetOpenOrders return all orders
 // If market is set to a specific order, GetOpenOrders return orders for this market
 func (b *Bittrex) GetOpenOrders(market string) (openOrders []OrderV3, err error) {
+	return b.GetOpenOrdersCtx(context.Background(), market)
+}
+
+// GetOpenOrdersCtx is the context-aware variant of GetOpenOrders.
+func (b *Bittrex) GetOpenOrdersCtx(ctx context.Context, market string) (openOrders []OrderV3, err error) {
 	resource := "orders/open"
 	if market == "" {
 		market = "all"
 	}
 	if market != "all" {
-		resource += "?marketSymbol=" + strings.ToUpper(market)
+		if market, err = b.cachedMarketSymbol(market); err != nil {
+			return
+		}
+		resource += "?marketSymbol=" + market
 	}
-	r, err := b.client.do("GET", resource, "", true)
+	r, err := b.client.doCtx(ctx, "GET", resource, "", true)
 	if err != nil {
 		return
 	}
@@ -351,11 +716,372 @@ func (b *Bittrex) GetOpenOrders(market string) (openOrders []OrderV3, err error)
 	return
 }
 
+// GetOpenOrdersWithSequence returns the open orders of every market along with the sequence
+// number of the account orders, to be matched with the sequence of the order websocket deltas.
+func (b *Bittrex) GetOpenOrdersWithSequence() (openOrders []OrderV3, sequence int, err error) {
+	return b.GetOpenOrdersWithSequenceCtx(context.Background())
+}
+
+// GetOpenOrdersWithSequenceCtx is the context-aware variant of GetOpenOrdersWithSequence.
+func (b *Bittrex) GetOpenOrdersWithSequenceCtx(ctx context.Context) (openOrders []OrderV3, sequence int, err error) {
+	r, header, err := b.client.doHeaderCtx(ctx, "GET", "orders/open", "", true)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(r, &openOrders); err != nil {
+		return
+	}
+	sequence, err = strconv.Atoi(header.Get("Sequence"))
+	return
+}
+
+// Conditional orders
+
+// CreateConditionalOrder places an order, cancels one, or both, when the last trade price of a
+// market crosses a trigger price. Set params.TrailingStopPercent instead of params.TriggerPrice
+// for a trailing stop.
+func (b *Bittrex) CreateConditionalOrder(params CreateConditionalOrderParams) (order ConditionalOrderV3, err error) {
+	return b.CreateConditionalOrderCtx(context.Background(), params)
+}
+
+// CreateConditionalOrderCtx is the context-aware variant of CreateConditionalOrder.
+func (b *Bittrex) CreateConditionalOrderCtx(ctx context.Context, params CreateConditionalOrderParams) (order ConditionalOrderV3, err error) {
+	p, err := b.conditionalOrderPayload(ctx, params)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	// A client conditional order id makes the creation idempotent, so it is safe to retry
+	r, _, err := b.client.doRetry(ctx, "POST", "conditional-orders", string(payload), true, params.ClientConditionalOrderID != "")
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &order)
+	return
+}
+
+// GetConditionalOrder returns a conditional order by id.
+func (b *Bittrex) GetConditionalOrder(conditionalOrderID string) (order ConditionalOrderV3, err error) {
+	return b.GetConditionalOrderCtx(context.Background(), conditionalOrderID)
+}
+
+// GetConditionalOrderCtx is the context-aware variant of GetConditionalOrder.
+func (b *Bittrex) GetConditionalOrderCtx(ctx context.Context, conditionalOrderID string) (order ConditionalOrderV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "conditional-orders/"+conditionalOrderID, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &order)
+	return
+}
+
+// CancelConditionalOrder cancels a conditional order that has not triggered yet.
+func (b *Bittrex) CancelConditionalOrder(conditionalOrderID string) (order ConditionalOrderV3, err error) {
+	return b.CancelConditionalOrderCtx(context.Background(), conditionalOrderID)
+}
+
+// CancelConditionalOrderCtx is the context-aware variant of CancelConditionalOrder.
+func (b *Bittrex) CancelConditionalOrderCtx(ctx context.Context, conditionalOrderID string) (order ConditionalOrderV3, err error) {
+	r, err := b.client.doCtx(ctx, "DELETE", "conditional-orders/"+conditionalOrderID, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &order)
+	return
+}
+
+// GetOpenConditionalOrders returns the conditional orders waiting for their trigger.
+// If market is set to "all", it returns those of every market.
+func (b *Bittrex) GetOpenConditionalOrders(market string) (orders []ConditionalOrderV3, err error) {
+	return b.GetOpenConditionalOrdersCtx(context.Background(), market)
+}
+
+// GetOpenConditionalOrdersCtx is the context-aware variant of GetOpenConditionalOrders.
+func (b *Bittrex) GetOpenConditionalOrdersCtx(ctx context.Context, market string) (orders []ConditionalOrderV3, err error) {
+	return b.getConditionalOrders(ctx, "conditional-orders/open", market)
+}
+
+// GetClosedConditionalOrders returns the conditional orders that triggered, failed or were cancelled.
+// If market is set to "all", it returns those of every market.
+func (b *Bittrex) GetClosedConditionalOrders(market string) (orders []ConditionalOrderV3, err error) {
+	return b.GetClosedConditionalOrdersCtx(context.Background(), market)
+}
+
+// GetClosedConditionalOrdersCtx is the context-aware variant of GetClosedConditionalOrders.
+func (b *Bittrex) GetClosedConditionalOrdersCtx(ctx context.Context, market string) (orders []ConditionalOrderV3, err error) {
+	return b.getConditionalOrders(ctx, "conditional-orders/closed", market)
+}
+
+func (b *Bittrex) getConditionalOrders(ctx context.Context, resource, market string) (orders []ConditionalOrderV3, err error) {
+	if market != "" && market != "all" {
+		if market, err = b.cachedMarketSymbol(market); err != nil {
+			return
+		}
+		resource += "?marketSymbol=" + market
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &orders)
+	return
+}
+
+// Subaccounts
+
+// GetSubaccounts returns every subaccount of the master account.
+func (b *Bittrex) GetSubaccounts() (subaccounts []SubaccountV3, err error) {
+	return b.GetSubaccountsCtx(context.Background())
+}
+
+// GetSubaccountsCtx is the context-aware variant of GetSubaccounts.
+func (b *Bittrex) GetSubaccountsCtx(ctx context.Context) (subaccounts []SubaccountV3, err error) {
+	params := PageParams{PageSize: 200}
+	for {
+		v, _ := query.Values(params)
+		var r []byte
+		r, err = b.client.doCtx(ctx, "GET", "subaccounts?"+v.Encode(), "", true)
+		if err != nil {
+			return
+		}
+		var page []SubaccountV3
+		if err = json.Unmarshal(r, &page); err != nil {
+			return
+		}
+		subaccounts = append(subaccounts, page...)
+		if len(page) < params.PageSize {
+			// The last page
+			return
+		}
+		params.NextPageToken = page[len(page)-1].ID
+	}
+}
+
+// GetSubaccount returns a subaccount by id.
+func (b *Bittrex) GetSubaccount(subaccountID string) (subaccount SubaccountV3, err error) {
+	return b.GetSubaccountCtx(context.Background(), subaccountID)
+}
+
+// GetSubaccountCtx is the context-aware variant of GetSubaccount.
+func (b *Bittrex) GetSubaccountCtx(ctx context.Context, subaccountID string) (subaccount SubaccountV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "subaccounts/"+subaccountID, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &subaccount)
+	return
+}
+
+// CreateSubaccount creates a subaccount. WithSubaccount returns a handle acting on its behalf.
+func (b *Bittrex) CreateSubaccount() (subaccount SubaccountV3, err error) {
+	return b.CreateSubaccountCtx(context.Background())
+}
+
+// CreateSubaccountCtx is the context-aware variant of CreateSubaccount.
+func (b *Bittrex) CreateSubaccountCtx(ctx context.Context) (subaccount SubaccountV3, err error) {
+	r, err := b.client.doCtx(ctx, "POST", "subaccounts", "{}", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &subaccount)
+	return
+}
+
+// Transfer moves funds from the account of the handle to a subaccount, or to the master account.
+// A RequestID is generated if params has none; it makes the transfer safe to retry.
+func (b *Bittrex) Transfer(params TransferParams) (transfer TransferV3, err error) {
+	return b.TransferCtx(context.Background(), params)
+}
+
+// TransferCtx is the context-aware variant of Transfer.
+func (b *Bittrex) TransferCtx(ctx context.Context, params TransferParams) (transfer TransferV3, err error) {
+	if params.CurrencySymbol == "" || !params.Amount.IsPositive() || (params.ToSubaccountID == "") != params.ToMasterAccount {
+		return transfer, ERR_TRANSFER_MISSING_PARAMETERS
+	}
+	if params.RequestID == "" {
+		params.RequestID = uuid.New().String()
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	// The request id makes the transfer idempotent
+	r, _, err := b.client.doRetry(ctx, "POST", "transfers", string(payload), true, true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &transfer)
+	return
+}
+
+// GetTransfer returns a transfer sent or received by the account of the handle.
+func (b *Bittrex) GetTransfer(transferID string) (transfer TransferV3, err error) {
+	return b.GetTransferCtx(context.Background(), transferID)
+}
+
+// GetTransferCtx is the context-aware variant of GetTransfer.
+func (b *Bittrex) GetTransferCtx(ctx context.Context, transferID string) (transfer TransferV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "transfers/"+transferID, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &transfer)
+	return
+}
+
+// GetSentTransfers returns a page of the transfers sent by the account of the handle.
+func (b *Bittrex) GetSentTransfers(params SentTransfersParams) (transfers []TransferV3, err error) {
+	return b.GetSentTransfersCtx(context.Background(), params)
+}
+
+// GetSentTransfersCtx is the context-aware variant of GetSentTransfers.
+func (b *Bittrex) GetSentTransfersCtx(ctx context.Context, params SentTransfersParams) (transfers []TransferV3, err error) {
+	return b.getTransfers(ctx, "transfers/sent", params)
+}
+
+// GetReceivedTransfers returns a page of the transfers received by the account of the handle.
+func (b *Bittrex) GetReceivedTransfers(params ReceivedTransfersParams) (transfers []TransferV3, err error) {
+	return b.GetReceivedTransfersCtx(context.Background(), params)
+}
+
+// GetReceivedTransfersCtx is the context-aware variant of GetReceivedTransfers.
+func (b *Bittrex) GetReceivedTransfersCtx(ctx context.Context, params ReceivedTransfersParams) (transfers []TransferV3, err error) {
+	return b.getTransfers(ctx, "transfers/received", params)
+}
+
+func (b *Bittrex) getTransfers(ctx context.Context, resource string, params interface{}) (transfers []TransferV3, err error) {
+	v, _ := query.Values(params)
+	if queryParams := v.Encode(); len(queryParams) != 0 {
+		resource += "?" + queryParams
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &transfers)
+	return
+}
+
 // Account
 
+// GetAccount returns the account of the API key, or the subaccount of the handle.
+func (b *Bittrex) GetAccount() (account AccountV3, err error) {
+	return b.GetAccountCtx(context.Background())
+}
+
+// GetAccountCtx is the context-aware variant of GetAccount.
+func (b *Bittrex) GetAccountCtx(ctx context.Context) (account AccountV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "account", "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &account)
+	return
+}
+
+// GetAccountVolume returns the 30-day trading volume of the account, in USD.
+func (b *Bittrex) GetAccountVolume() (volume AccountVolumeV3, err error) {
+	return b.GetAccountVolumeCtx(context.Background())
+}
+
+// GetAccountVolumeCtx is the context-aware variant of GetAccountVolume.
+func (b *Bittrex) GetAccountVolumeCtx(ctx context.Context) (volume AccountVolumeV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "account/volume", "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &volume)
+	return
+}
+
+// GetTradingFees returns the maker and taker commission rates of the account on every market,
+// which follow its fee tier.
+func (b *Bittrex) GetTradingFees() (fees []TradingFeeV3, err error) {
+	return b.GetTradingFeesCtx(context.Background())
+}
+
+// GetTradingFeesCtx is the context-aware variant of GetTradingFees.
+func (b *Bittrex) GetTradingFeesCtx(ctx context.Context) (fees []TradingFeeV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "account/fees/trading", "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &fees)
+	return
+}
+
+// GetTradingFee returns the maker and taker commission rates of the account on a market.
+func (b *Bittrex) GetTradingFee(market string) (fee TradingFeeV3, err error) {
+	return b.GetTradingFeeCtx(context.Background(), market)
+}
+
+// GetTradingFeeCtx is the context-aware variant of GetTradingFee.
+func (b *Bittrex) GetTradingFeeCtx(ctx context.Context, market string) (fee TradingFeeV3, err error) {
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	r, err := b.client.doCtx(ctx, "GET", "account/fees/trading/"+market, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &fee)
+	return
+}
+
+// GetMarketPermissions returns whether the account may view, buy and sell on market.
+// If market is set to "all", it returns the permissions on every market.
+func (b *Bittrex) GetMarketPermissions(market string) (permissions []MarketPermissionV3, err error) {
+	return b.GetMarketPermissionsCtx(context.Background(), market)
+}
+
+// GetMarketPermissionsCtx is the context-aware variant of GetMarketPermissions.
+func (b *Bittrex) GetMarketPermissionsCtx(ctx context.Context, market string) (permissions []MarketPermissionV3, err error) {
+	resource := "account/permissions/markets"
+	if market != "" && market != "all" {
+		if market, err = b.cachedMarketSymbol(market); err != nil {
+			return
+		}
+		resource += "/" + market
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &permissions)
+	return
+}
+
+// GetCurrencyPermissions returns whether the account may view, deposit and withdraw currency.
+// If currency is set to "all", it returns the permissions on every currency.
+func (b *Bittrex) GetCurrencyPermissions(currency string) (permissions []CurrencyPermissionV3, err error) {
+	return b.GetCurrencyPermissionsCtx(context.Background(), currency)
+}
+
+// GetCurrencyPermissionsCtx is the context-aware variant of GetCurrencyPermissions.
+func (b *Bittrex) GetCurrencyPermissionsCtx(ctx context.Context, currency string) (permissions []CurrencyPermissionV3, err error) {
+	resource := "account/permissions/currencies"
+	if currency != "" && currency != "all" {
+		resource += "/" + normalizeSymbol(currency)
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &permissions)
+	return
+}
+
 // GetBalances is used to retrieve all balances from your account
 func (b *Bittrex) GetBalances() (balances []BalanceV3, err error) {
-	r, err := b.client.do("GET", "balances", "", true)
+	return b.GetBalancesCtx(context.Background())
+}
+
+// GetBalancesCtx is the context-aware variant of GetBalances.
+func (b *Bittrex) GetBalancesCtx(ctx context.Context) (balances []BalanceV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "balances", "", true)
 	if err != nil {
 		return
 	}
@@ -366,7 +1092,12 @@ func (b *Bittrex) GetBalances() (balances []BalanceV3, err error) {
 // Getbalance is used to retrieve the balance from your account for a specific currency.
 // currency: a string literal for the currency (ex: LTC)
 func (b *Bittrex) GetBalance(currency string) (balance Balance, err error) {
-	r, err := b.client.do("GET", fmt.Sprintf("balances/%s", strings.ToUpper(currency)), "", true)
+	return b.GetBalanceCtx(context.Background(), currency)
+}
+
+// GetBalanceCtx is the context-aware variant of GetBalance.
+func (b *Bittrex) GetBalanceCtx(ctx context.Context, currency string) (balance Balance, err error) {
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("balances/%s", normalizeSymbol(currency)), "", true)
 	if err != nil {
 		return
 	}
@@ -377,13 +1108,18 @@ func (b *Bittrex) GetBalance(currency string) (balance Balance, err error) {
 // GetDepositAddress is sed to generate or retrieve an address for a specific currency.
 // currency a string literal for the currency (ie. BTC)
 func (b *Bittrex) GetDepositAddress(currency string) (address AddressV3, err error) {
-	var addressParams = AddressParams{CurrencySymbol: currency}
+	return b.GetDepositAddressCtx(context.Background(), currency)
+}
+
+// GetDepositAddressCtx is the context-aware variant of GetDepositAddress.
+func (b *Bittrex) GetDepositAddressCtx(ctx context.Context, currency string) (address AddressV3, err error) {
+	var addressParams = AddressParams{CurrencySymbol: normalizeSymbol(currency)}
 	payload, err := json.Marshal(addressParams)
 	if err != nil {
 		return
 	}
-	r, err := b.client.do("GET", fmt.Sprintf("addresses/%s", currency), "", true)
-	/* r, err := b.client.do("POST", "addresses", string(payload), true)
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("addresses/%s", normalizeSymbol(currency)), "", true)
+	/* r, err := b.client.doCtx(ctx, "POST", "addresses", string(payload), true)
 	if err != nil {
 		return
 	} */
@@ -393,9 +1129,9 @@ func (b *Bittrex) GetDepositAddress(currency string) (address AddressV3, err err
 	}
 
 	if address.CryptoAddress == "" {
-		log.Println("needs to create new address")
-		_, _ = b.client.do("POST", "addresses", string(payload), true)
-		r, err = b.client.do("GET", fmt.Sprintf("addresses/%s", currency), "", true)
+		b.client.logger.Printf("needs to create new address")
+		_, _ = b.client.doCtx(ctx, "POST", "addresses", string(payload), true)
+		r, err = b.client.doCtx(ctx, "GET", fmt.Sprintf("addresses/%s", normalizeSymbol(currency)), "", true)
 		if err != nil {
 			return
 		}
@@ -410,17 +1146,22 @@ func (b *Bittrex) GetDepositAddress(currency string) (address AddressV3, err err
 // quantity decimal.Decimal the quantity of coins to withdraw
 // tag string an optional name for the withdrawal address
 func (b *Bittrex) Withdraw(address, currency string, quantity decimal.Decimal, tag string) (withdraw WithdrawalV3, err error) {
+	return b.WithdrawCtx(context.Background(), address, currency, quantity, tag)
+}
+
+// WithdrawCtx is the context-aware variant of Withdraw.
+func (b *Bittrex) WithdrawCtx(ctx context.Context, address, currency string, quantity decimal.Decimal, tag string) (withdraw WithdrawalV3, err error) {
 	if address == "" || currency == "" || quantity.LessThan(decimal.NewFromFloat(0.0)) {
 		return withdraw, ERR_WITHDRAWAL_MISSING_PARAMETERS
 	}
 	var params = WithdrawalParams{
-		CurrencySymbol:   currency,
+		CurrencySymbol:   normalizeSymbol(currency),
 		Quantity:         quantity.String(),
 		CryptoAddress:    address,
 		CryptoAddressTag: "",
 	}
 	payload, err := json.Marshal(params)
-	r, err := b.client.do("POST", "withdrawals", string(payload), true)
+	r, err := b.client.doCtx(ctx, "POST", "withdrawals", string(payload), true)
 	if err != nil {
 		return
 	}
@@ -431,9 +1172,14 @@ func (b *Bittrex) Withdraw(address, currency string, quantity decimal.Decimal, t
 // GetOpenWithdrawals is used to retrieve your open withdrawal history
 // currency string a string literal for the currency (ie. BTC). If set to "", will return for all currencies
 func (b *Bittrex) GetOpenWithdrawals(currency string, status WithdrawalStatus) (withdrawals []WithdrawalV3, err error) {
+	return b.GetOpenWithdrawalsCtx(context.Background(), currency, status)
+}
+
+// GetOpenWithdrawalsCtx is the context-aware variant of GetOpenWithdrawals.
+func (b *Bittrex) GetOpenWithdrawalsCtx(ctx context.Context, currency string, status WithdrawalStatus) (withdrawals []WithdrawalV3, err error) {
 	var params = WithdrawalHistoryParams{
 		Status:         string(status),
-		CurrencySymbol: strings.ToUpper(currency),
+		CurrencySymbol: normalizeSymbol(currency),
 	}
 	v, _ := query.Values(params)
 	queryParams := v.Encode()
@@ -441,7 +1187,7 @@ func (b *Bittrex) GetOpenWithdrawals(currency string, status WithdrawalStatus) (
 	if len(queryParams) != 0 {
 		resource += "?"
 	}
-	r, err := b.client.do("GET", resource+queryParams, "", true)
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
 	if err != nil {
 		return
 	}
@@ -449,24 +1195,40 @@ func (b *Bittrex) GetOpenWithdrawals(currency string, status WithdrawalStatus) (
 	return
 }
 
-// GetClosedWithdrawals is used to retrieve your closed withdrawal history
+// GetClosedWithdrawals is used to retrieve your most recent closed withdrawals, a page of 100 at most.
 // currency string a string literal for the currency (ie. BTC). If set to "all", will return for all currencies
-// TODO Add more parameters according to https://bittrex.github.io/api/v3#operation--withdrawals-closed-get
+// ClosedWithdrawalsIter walks the whole history.
 func (b *Bittrex) GetClosedWithdrawals(currency string, status WithdrawalStatus) (withdrawals []WithdrawalV3, err error) {
+	return b.GetClosedWithdrawalsCtx(context.Background(), currency, status)
+}
+
+// GetClosedWithdrawalsCtx is the context-aware variant of GetClosedWithdrawals.
+func (b *Bittrex) GetClosedWithdrawalsCtx(ctx context.Context, currency string, status WithdrawalStatus) (withdrawals []WithdrawalV3, err error) {
 	var params = WithdrawalHistoryParams{}
 	if currency != "all" {
-		params.CurrencySymbol = currency
+		params.CurrencySymbol = normalizeSymbol(currency)
 	}
 	if status != "" {
 		params.Status = string(status)
 	}
+	return b.GetClosedWithdrawalsPageCtx(ctx, params)
+}
+
+// GetClosedWithdrawalsPage returns a page of closed withdrawals.
+func (b *Bittrex) GetClosedWithdrawalsPage(params WithdrawalHistoryParams) (withdrawals []WithdrawalV3, err error) {
+	return b.GetClosedWithdrawalsPageCtx(context.Background(), params)
+}
+
+// GetClosedWithdrawalsPageCtx is the context-aware variant of GetClosedWithdrawalsPage.
+func (b *Bittrex) GetClosedWithdrawalsPageCtx(ctx context.Context, params WithdrawalHistoryParams) (withdrawals []WithdrawalV3, err error) {
+	params.CurrencySymbol = normalizeSymbol(params.CurrencySymbol)
 	v, _ := query.Values(params)
 	queryParams := v.Encode()
 	resource := "withdrawals/closed"
 	if len(queryParams) != 0 {
 		resource += "?"
 	}
-	r, err := b.client.do("GET", resource+queryParams, "", true)
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
 	if err != nil {
 		return
 	}
@@ -477,7 +1239,12 @@ func (b *Bittrex) GetClosedWithdrawals(currency string, status WithdrawalStatus)
 // GetWithdrawalByTxId is used to retrieve information of a withdrawal by txid.
 // txid string a string literal for the on chain transaction id.
 func (b *Bittrex) GetWithdrawalByTxId(txid string) (withdrawal WithdrawalV3, err error) {
-	r, err := b.client.do("GET", fmt.Sprintf("withdrawals/ByTxId/%s", txid), "", true)
+	return b.GetWithdrawalByTxIdCtx(context.Background(), txid)
+}
+
+// GetWithdrawalByTxIdCtx is the context-aware variant of GetWithdrawalByTxId.
+func (b *Bittrex) GetWithdrawalByTxIdCtx(ctx context.Context, txid string) (withdrawal WithdrawalV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("withdrawals/ByTxId/%s", txid), "", true)
 	if err != nil {
 		return
 	}
@@ -495,9 +1262,14 @@ func (b *Bittrex) GetWithdrawalByTxId(txid string) (withdrawal WithdrawalV3, err
 // GetOpenDepositHistory is used to retrieve your open deposit history
 // currency string a string literal for the currency (ie. BTC). If set to "all", will return for all currencies
 func (b *Bittrex) GetOpenDepositHistory(currency string, status DepositStatus) (deposits []DepositV3, err error) {
+	return b.GetOpenDepositHistoryCtx(context.Background(), currency, status)
+}
+
+// GetOpenDepositHistoryCtx is the context-aware variant of GetOpenDepositHistory.
+func (b *Bittrex) GetOpenDepositHistoryCtx(ctx context.Context, currency string, status DepositStatus) (deposits []DepositV3, err error) {
 	var params = DepositHistoryParams{}
 	if currency != "all" {
-		params.CurrencySymbol = currency
+		params.CurrencySymbol = normalizeSymbol(currency)
 	}
 	if status != "" {
 		params.Status = string(status)
@@ -508,7 +1280,7 @@ func (b *Bittrex) GetOpenDepositHistory(currency string, status DepositStatus) (
 	if len(queryParams) != 0 {
 		resource += "?"
 	}
-	r, err := b.client.do("GET", resource+queryParams, "", true)
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
 	if err != nil {
 		return
 	}
@@ -523,69 +1295,279 @@ func (b *Bittrex) GetOpenDepositHistory(currency string, status DepositStatus) (
 	return
 }
 
-// GetClosedDepositHistory is used to retrieve your closed deposit history
+// GetClosedDepositHistory is used to retrieve your most recent closed deposits, a page of 100 at most.
 // currency string a string literal for the currency (ie. BTC). If set to "all", will return for all currencies
+// ClosedDepositsIter walks the whole history.
 func (b *Bittrex) GetClosedDepositHistory(currency string, status DepositStatus) (deposits []DepositV3, err error) {
+	return b.GetClosedDepositHistoryCtx(context.Background(), currency, status)
+}
+
+// GetClosedDepositHistoryCtx is the context-aware variant of GetClosedDepositHistory.
+func (b *Bittrex) GetClosedDepositHistoryCtx(ctx context.Context, currency string, status DepositStatus) (deposits []DepositV3, err error) {
 	var params = DepositHistoryParams{}
 	if currency != "all" {
-		params.CurrencySymbol = currency
+		params.CurrencySymbol = normalizeSymbol(currency)
 	}
 	if status != "" {
 		params.Status = string(status)
 	}
+	return b.GetClosedDepositsPageCtx(ctx, params)
+}
+
+// GetClosedDepositsPage returns a page of closed deposits.
+func (b *Bittrex) GetClosedDepositsPage(params DepositHistoryParams) (deposits []DepositV3, err error) {
+	return b.GetClosedDepositsPageCtx(context.Background(), params)
+}
+
+// GetClosedDepositsPageCtx is the context-aware variant of GetClosedDepositsPage.
+func (b *Bittrex) GetClosedDepositsPageCtx(ctx context.Context, params DepositHistoryParams) (deposits []DepositV3, err error) {
+	params.CurrencySymbol = normalizeSymbol(params.CurrencySymbol)
 	v, _ := query.Values(params)
 	queryParams := v.Encode()
 	resource := "deposits/closed"
 	if len(queryParams) != 0 {
 		resource += "?"
 	}
-	r, err := b.client.do("GET", resource+queryParams, "", true)
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
 	if err != nil {
 		return
 	}
-	// var response json.RawMessage
-	if err = json.Unmarshal(r, &deposits); err != nil {
+	err = json.Unmarshal(r, &deposits)
+	return
+}
+
+// GetOrder is used to get a single order by its id.
+func (b *Bittrex) GetOrder(orderID string) (order OrderV3, err error) {
+	return b.GetOrderCtx(context.Background(), orderID)
+}
+
+// GetOrderCtx is the context-aware variant of GetOrder.
+func (b *Bittrex) GetOrderCtx(ctx context.Context, orderID string) (order OrderV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "orders/"+orderID, "", true)
+	if err != nil {
 		return
 	}
-	/*if err = handleErr(response); err != nil {
+	err = json.Unmarshal(r, &order)
+	return
+}
+
+// GetOrderByClientOrderID is used to get a single order by the ClientOrderID given at its creation.
+// It looks through the open orders, then walks the whole closed orders history, and returns an
+// error matching ERR_NOT_FOUND if none has clientOrderID.
+func (b *Bittrex) GetOrderByClientOrderID(clientOrderID string) (order OrderV3, err error) {
+	return b.GetOrderByClientOrderIDCtx(context.Background(), clientOrderID)
+}
+
+// GetOrderByClientOrderIDCtx is the context-aware variant of GetOrderByClientOrderID.
+func (b *Bittrex) GetOrderByClientOrderIDCtx(ctx context.Context, clientOrderID string) (order OrderV3, err error) {
+	if clientOrderID == "" {
+		return order, fmt.Errorf("%w: clientOrderID is required", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	open, err := b.GetOpenOrdersCtx(ctx, "all")
+	if err != nil {
+		return order, err
+	}
+	for _, o := range open {
+		if o.ClientOrderID == clientOrderID {
+			return o, nil
+		}
+	}
+	it := b.ClosedOrdersIter(ctx, ClosedOrdersParams{PageParams: PageParams{PageSize: 200}})
+	for it.Next() {
+		if o := it.Order(); o.ClientOrderID == clientOrderID {
+			return o, nil
+		}
+	}
+	if err = it.Err(); err != nil {
+		return order, err
+	}
+	return order, fmt.Errorf("order with client order id %s: %w", clientOrderID, ERR_NOT_FOUND)
+}
+
+// GetOrderExecutions is used to get the executions (fills) of an order.
+func (b *Bittrex) GetOrderExecutions(orderID string) (executions []ExecutionV3, err error) {
+	return b.GetOrderExecutionsCtx(context.Background(), orderID)
+}
+
+// GetOrderExecutionsCtx is the context-aware variant of GetOrderExecutions.
+func (b *Bittrex) GetOrderExecutionsCtx(ctx context.Context, orderID string) (executions []ExecutionV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "orders/"+orderID+"/executions", "", true)
+	if err != nil {
 		return
-	}*/
-	// err = json.Unmarshal(response, &deposits)
+	}
+	err = json.Unmarshal(r, &executions)
+	return
+}
+
+// GetExecutions returns a page of the executions (fills) of your orders, most recent first.
+// ExecutionsIter walks the whole history.
+func (b *Bittrex) GetExecutions(params ExecutionsParams) (executions []ExecutionV3, err error) {
+	return b.GetExecutionsCtx(context.Background(), params)
+}
+
+// GetExecutionsCtx is the context-aware variant of GetExecutions.
+func (b *Bittrex) GetExecutionsCtx(ctx context.Context, params ExecutionsParams) (executions []ExecutionV3, err error) {
+	if params.MarketSymbol != "" {
+		if params.MarketSymbol, err = b.cachedMarketSymbol(params.MarketSymbol); err != nil {
+			return
+		}
+	}
+	v, _ := query.Values(params)
+	queryParams := v.Encode()
+	resource := "executions"
+	if len(queryParams) != 0 {
+		resource += "?"
+	}
+	r, err := b.client.doCtx(ctx, "GET", resource+queryParams, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &executions)
 	return
 }
 
-func (b *Bittrex) GetOrder(order_uuid string) (order Order2, err error) {
+// GetExecution is used to get a single execution by its id.
+func (b *Bittrex) GetExecution(executionID string) (execution ExecutionV3, err error) {
+	return b.GetExecutionCtx(context.Background(), executionID)
+}
 
-	resource := "account/getorder?uuid=" + order_uuid
+// GetExecutionCtx is the context-aware variant of GetExecution.
+func (b *Bittrex) GetExecutionCtx(ctx context.Context, executionID string) (execution ExecutionV3, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "executions/"+executionID, "", true)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &execution)
+	return
+}
 
-	r, err := b.client.do("GET", resource, "", true)
+// GetLastExecutionID returns the id of your most recent execution, empty if there is none.
+func (b *Bittrex) GetLastExecutionID() (executionID string, err error) {
+	return b.GetLastExecutionIDCtx(context.Background())
+}
+
+// GetLastExecutionIDCtx is the context-aware variant of GetLastExecutionID.
+func (b *Bittrex) GetLastExecutionIDCtx(ctx context.Context) (executionID string, err error) {
+	r, err := b.client.doCtx(ctx, "GET", "executions/last-id", "", true)
 	if err != nil {
 		return
 	}
-	var response jsonResponse
-	if err = json.Unmarshal(r, &response); err != nil {
+	var last struct {
+		LastID string `json:"lastId"`
+	}
+	err = json.Unmarshal(r, &last)
+	return last.LastID, err
+}
+
+// GetCandles is used to get the recent candles of a market: the last day of MINUTE_1 and MINUTE_5
+// candles, the last 31 days of HOUR_1 candles or the last 366 days of DAY_1 candles.
+// candleType: CANDLE_TRADE or CANDLE_MIDPOINT
+func (b *Bittrex) GetCandles(market string, candleType CandleType, interval CandleInterval) (candles []CandleV3, err error) {
+	return b.GetCandlesCtx(context.Background(), market, candleType, interval)
+}
+
+// GetCandlesCtx is the context-aware variant of GetCandles.
+func (b *Bittrex) GetCandlesCtx(ctx context.Context, market string, candleType CandleType, interval CandleInterval) (candles []CandleV3, err error) {
+	if interval.Duration() == 0 {
+		return nil, errors.New("wrong interval")
+	}
+	if market, err = b.cachedMarketSymbol(market); err != nil {
 		return
 	}
-	if err = json.Unmarshal(r, &response); err != nil {
+	r, err := b.client.doCtx(ctx, "GET", fmt.Sprintf("markets/%s/candles/%s/%s/recent", market, candleType, interval), "", false)
+	if err != nil {
 		return
 	}
-	err = json.Unmarshal(response.Result, &order)
+	err = json.Unmarshal(r, &candles)
 	return
 }
 
+// GetHistoricalCandles is used to get the candles of a market over the period containing date:
+// its day for MINUTE_1 and MINUTE_5 candles, its month for HOUR_1 candles, its year for DAY_1 candles.
+func (b *Bittrex) GetHistoricalCandles(market string, candleType CandleType, interval CandleInterval, date time.Time) (candles []CandleV3, err error) {
+	return b.GetHistoricalCandlesCtx(context.Background(), market, candleType, interval, date)
+}
+
+// GetHistoricalCandlesCtx is the context-aware variant of GetHistoricalCandles.
+func (b *Bittrex) GetHistoricalCandlesCtx(ctx context.Context, market string, candleType CandleType, interval CandleInterval, date time.Time) (candles []CandleV3, err error) {
+	if interval.Duration() == 0 {
+		return nil, errors.New("wrong interval")
+	}
+	if market, err = b.cachedMarketSymbol(market); err != nil {
+		return
+	}
+	start, _ := interval.historicalPeriod(date)
+	resource := fmt.Sprintf("markets/%s/candles/%s/%s/historical/%s", market, candleType, interval, interval.historicalPath(start))
+	r, err := b.client.doCtx(ctx, "GET", resource, "", false)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(r, &candles)
+	return
+}
+
+// BackfillCandles returns the candles of a market starting in [start, end), oldest first.
+// It walks the historical endpoints period by period, and the recent endpoint for the period in progress.
+// Intervals without trades between the first and the last candle are filled with flat candles
+// at the previous close and no volume, so the series is continuous.
+func (b *Bittrex) BackfillCandles(market string, candleType CandleType, interval CandleInterval, start, end time.Time) (candles []CandleV3, err error) {
+	return b.BackfillCandlesCtx(context.Background(), market, candleType, interval, start, end)
+}
+
+// BackfillCandlesCtx is the context-aware variant of BackfillCandles.
+func (b *Bittrex) BackfillCandlesCtx(ctx context.Context, market string, candleType CandleType, interval CandleInterval, start, end time.Time) (candles []CandleV3, err error) {
+	if interval.Duration() == 0 {
+		return nil, errors.New("wrong interval")
+	}
+	now := b.client.clock.Now()
+	if end.After(now) {
+		end = now
+	}
+
+	var all []CandleV3
+	for period, periodEnd := interval.historicalPeriod(start); period.Before(end); period, periodEnd = interval.historicalPeriod(periodEnd) {
+		var batch []CandleV3
+		if periodEnd.After(now) {
+			// The period in progress is not historical yet
+			batch, err = b.GetCandlesCtx(ctx, market, candleType, interval)
+		} else {
+			batch, err = b.GetHistoricalCandlesCtx(ctx, market, candleType, interval, period)
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, batch...)
+	}
+	return continuousCandles(all, interval.Duration(), start, end), nil
+}
+
 // GetTicks is used to get ticks history values for a market.
+// market may be in either notation, it is sent in legacy notation (BTC-LTC).
 // Interval can be -> ["oneMin", "fiveMin", "thirtyMin", "hour", "day"]
+//
+// Deprecated: GetTicks calls the retired v2.0 API. Use GetCandles or BackfillCandles.
 func (b *Bittrex) GetTicks(market string, interval string) ([]Candle, error) {
+	return b.GetTicksCtx(context.Background(), market, interval)
+}
+
+// GetTicksCtx is the context-aware variant of GetTicks.
+//
+// Deprecated: use GetCandlesCtx or BackfillCandlesCtx.
+func (b *Bittrex) GetTicksCtx(ctx context.Context, market string, interval string) ([]Candle, error) {
 	_, ok := CANDLE_INTERVALS[interval]
 	if !ok {
 		return nil, errors.New("wrong interval")
 	}
+	m, err := b.cachedMarketSymbol(market)
+	if err != nil {
+		return nil, err
+	}
 
 	endpoint := fmt.Sprintf(
-		"https://bittrex.com/Api/v2.0/pub/market/GetTicks?tickInterval=%s&marketName=%s&_=%d",
-		interval, strings.ToUpper(market), rand.Int(),
+		"%spub/market/GetTicks?tickInterval=%s&marketName=%s&_=%d",
+		b.client.legacyBaseURL, interval, MarketSymbol(m).Legacy(), rand.Int(),
 	)
-	r, err := b.client.do("GET", endpoint, "", false)
+	r, err := b.client.doCtx(ctx, "GET", endpoint, "", false)
 	if err != nil {
 		return nil, fmt.Errorf("could not get market ticks: %v", err)
 	}
@@ -608,17 +1590,31 @@ func (b *Bittrex) GetTicks(market string, interval string) ([]Candle, error) {
 }
 
 // GetLatestTick returns array with a single element latest candle object
+// market may be in either notation, it is sent in legacy notation (BTC-LTC).
+//
+// Deprecated: GetLatestTick calls the retired v2.0 API. Use GetCandles.
 func (b *Bittrex) GetLatestTick(market string, interval string) ([]Candle, error) {
+	return b.GetLatestTickCtx(context.Background(), market, interval)
+}
+
+// GetLatestTickCtx is the context-aware variant of GetLatestTick.
+//
+// Deprecated: use GetCandlesCtx.
+func (b *Bittrex) GetLatestTickCtx(ctx context.Context, market string, interval string) ([]Candle, error) {
 	_, ok := CANDLE_INTERVALS[interval]
 	if !ok {
 		return nil, errors.New("wrong interval")
 	}
+	m, err := b.cachedMarketSymbol(market)
+	if err != nil {
+		return nil, err
+	}
 
 	endpoint := fmt.Sprintf(
-		"https://bittrex.com/Api/v2.0/pub/market/GetLatestTick?tickInterval=%s&marketName=%s&_=%d",
-		interval, strings.ToUpper(market), rand.Int(),
+		"%spub/market/GetLatestTick?tickInterval=%s&marketName=%s&_=%d",
+		b.client.legacyBaseURL, interval, MarketSymbol(m).Legacy(), rand.Int(),
 	)
-	r, err := b.client.do("GET", endpoint, "", false)
+	r, err := b.client.doCtx(ctx, "GET", endpoint, "", false)
 	if err != nil {
 		return nil, fmt.Errorf("could not get market ticks: %v", err)
 	}
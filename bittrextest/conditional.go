@@ -0,0 +1,174 @@
+package bittrextest
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// conditionalOrder is a conditional order along with the state of its trailing stop.
+type conditionalOrder struct {
+	ConditionalOrder
+	extreme decimal.Decimal // highest (LTE) or lowest (GTE) price seen by a trailing stop
+}
+
+// lastRate returns the rate of the last trade of a market.
+func lastRate(m *market) (decimal.Decimal, bool) {
+	if len(m.trades) == 0 {
+		return decimal.Zero, false
+	}
+	return m.trades[len(m.trades)-1].Rate, true
+}
+
+// placeConditionalOrder validates and records a new conditional order for an account.
+func (s *Server) placeConditionalOrder(a *account, req ConditionalOrder) (*ConditionalOrder, *httpError) {
+	m, ok := s.markets[req.MarketSymbol]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "MARKET_DOES_NOT_EXIST")
+	}
+	if req.Operand != operandLTE && req.Operand != operandGTE {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_OPERAND")
+	}
+	trigger, trailing := positive(req.TriggerPrice), positive(req.TrailingStopPercent)
+	if (trigger == nil) == (trailing == nil) || trailing != nil && trailing.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_TRIGGER_PRICE")
+	}
+	if req.OrderToCreate == nil && req.OrderToCancel == nil {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_CONDITIONAL_ORDER")
+	}
+	if req.OrderToCreate != nil && req.OrderToCreate.MarketSymbol != req.MarketSymbol {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_CONDITIONAL_ORDER")
+	}
+	if link := req.OrderToCancel; link != nil {
+		switch link.Type {
+		case linkedOrder:
+			if o, ok := a.orders[link.ID]; !ok || o.Status != statusOpen {
+				return nil, newHTTPError(http.StatusNotFound, "ORDER_TO_CANCEL_NOT_FOUND")
+			}
+		case linkedConditionalOrder:
+			if c, ok := a.conditionalOrders[link.ID]; !ok || c.Status != conditionalOpen {
+				return nil, newHTTPError(http.StatusNotFound, "ORDER_TO_CANCEL_NOT_FOUND")
+			}
+		default:
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_CONDITIONAL_ORDER")
+		}
+	}
+	if req.ClientConditionalOrderID != "" {
+		for _, id := range a.conditionalIDs {
+			if a.conditionalOrders[id].ClientConditionalOrderID == req.ClientConditionalOrderID {
+				return nil, newHTTPError(http.StatusConflict, "DUPLICATE_CLIENT_ORDER_ID")
+			}
+		}
+	}
+
+	now := s.now()
+	c := &conditionalOrder{ConditionalOrder: ConditionalOrder{
+		ID:                       uuid.New().String(),
+		MarketSymbol:             m.Symbol,
+		Operand:                  req.Operand,
+		TriggerPrice:             trigger,
+		TrailingStopPercent:      trailing,
+		OrderToCreate:            req.OrderToCreate,
+		OrderToCancel:            req.OrderToCancel,
+		ClientConditionalOrderID: req.ClientConditionalOrderID,
+		Status:                   conditionalOpen,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}}
+	c.extreme, _ = lastRate(m)
+	a.conditionalOrders[c.ID] = c
+	a.conditionalIDs = append(a.conditionalIDs, c.ID)
+	return &c.ConditionalOrder, nil
+}
+
+// closeConditional closes a conditional order with status.
+func (s *Server) closeConditional(c *conditionalOrder, status string) {
+	now := s.now()
+	c.Status = status
+	c.UpdatedAt = now
+	c.ClosedAt = &now
+}
+
+// cancelConditional cancels an open conditional order, and those linked to it.
+func (s *Server) cancelConditional(a *account, c *conditionalOrder) {
+	s.closeConditional(c, conditionalCancelled)
+	s.cancelLinked(a, linkedConditionalOrder, c.ID)
+}
+
+// cancelLinked cancels the open conditional orders whose order to cancel is the given order,
+// as links between orders are reciprocal.
+func (s *Server) cancelLinked(a *account, linkType, id string) {
+	for _, cid := range a.conditionalIDs {
+		c := a.conditionalOrders[cid]
+		if c.Status == conditionalOpen && c.OrderToCancel != nil && c.OrderToCancel.Type == linkType && c.OrderToCancel.ID == id {
+			s.cancelConditional(a, c)
+		}
+	}
+}
+
+// triggerConditionalOrders fires the open conditional orders of a market reached by its last trade.
+func (s *Server) triggerConditionalOrders(m *market) {
+	rate, ok := lastRate(m)
+	if !ok {
+		return
+	}
+	for _, a := range s.accounts() {
+		for _, id := range a.conditionalIDs {
+			c := a.conditionalOrders[id]
+			if c.Status != conditionalOpen || c.MarketSymbol != m.Symbol {
+				continue
+			}
+			if c.reached(rate) {
+				s.fire(a, c)
+			}
+		}
+	}
+}
+
+// reached reports whether rate triggers the order, moving its trailing stop first.
+func (c *conditionalOrder) reached(rate decimal.Decimal) bool {
+	trigger := c.TriggerPrice
+	if c.TrailingStopPercent != nil {
+		ratio := c.TrailingStopPercent.Div(decimal.NewFromInt(100))
+		if c.Operand == operandLTE {
+			c.extreme = decimal.Max(c.extreme, rate)
+			ratio = ratio.Neg()
+		} else if c.extreme.IsZero() || rate.LessThan(c.extreme) {
+			c.extreme = rate
+		}
+		t := c.extreme.Mul(decimal.NewFromInt(1).Add(ratio))
+		trigger = &t
+	}
+	if c.Operand == operandLTE {
+		return rate.LessThanOrEqual(*trigger)
+	}
+	return rate.GreaterThanOrEqual(*trigger)
+}
+
+// fire cancels the order to cancel of a triggered conditional order, then places its order to create.
+func (s *Server) fire(a *account, c *conditionalOrder) {
+	s.closeConditional(c, conditionalCompleted)
+	if link := c.OrderToCancel; link != nil {
+		switch link.Type {
+		case linkedOrder:
+			if o, ok := a.orders[link.ID]; ok && o.Status == statusOpen {
+				s.close(a, o)
+			}
+		case linkedConditionalOrder:
+			if linked, ok := a.conditionalOrders[link.ID]; ok && linked.Status == conditionalOpen {
+				s.cancelConditional(a, linked)
+			}
+		}
+	}
+	s.cancelLinked(a, linkedConditionalOrder, c.ID)
+	if c.OrderToCreate != nil {
+		o, herr := s.placeOrder(a, *c.OrderToCreate)
+		if herr != nil {
+			c.Status = conditionalFailed
+			c.OrderCreationErrorCode = herr.body.Code
+			return
+		}
+		c.CreatedOrderID = o.ID
+	}
+}
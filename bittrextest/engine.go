@@ -0,0 +1,519 @@
+package bittrextest
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Order statuses, types and directions used by the matching engine
+const (
+	statusOpen   = "OPEN"
+	statusClosed = "CLOSED"
+
+	typeMarket        = "MARKET"
+	typeLimit         = "LIMIT"
+	typeCeilingLimit  = "CEILING_LIMIT"
+	typeCeilingMarket = "CEILING_MARKET"
+
+	conditionalOpen      = "OPEN"
+	conditionalCompleted = "COMPLETED"
+	conditionalCancelled = "CANCELLED"
+	conditionalFailed    = "FAILED"
+
+	operandLTE = "LTE"
+	operandGTE = "GTE"
+
+	linkedOrder            = "ORDER"
+	linkedConditionalOrder = "CONDITIONAL_ORDER"
+
+	buy  = "BUY"
+	sell = "SELL"
+
+	goodTilCancelled         = "GOOD_TIL_CANCELLED"
+	immediateOrCancel        = "IMMEDIATE_OR_CANCEL"
+	fillOrKill               = "FILL_OR_KILL"
+	postOnlyGoodTilCancelled = "POST_ONLY_GOOD_TIL_CANCELLED"
+)
+
+// quantityPrecision is the number of decimals of computed quantities
+const quantityPrecision = 8
+
+// ledger is the balance of one currency.
+type ledger struct {
+	total     decimal.Decimal
+	reserved  decimal.Decimal
+	updatedAt time.Time
+}
+
+func (l *ledger) available() decimal.Decimal {
+	return l.total.Sub(l.reserved)
+}
+
+// account holds the balances and the trading history of one API key.
+type account struct {
+	balances          map[string]*ledger
+	orders            map[string]*Order
+	orderIDs          []string // creation order
+	orderSequence     int64    // bumped on every order change, as the order websocket channel
+	conditionalOrders map[string]*conditionalOrder
+	conditionalIDs    []string // creation order
+	reserved          map[string]decimal.Decimal
+	executions        []*Execution
+	addresses         map[string]*Address
+	deposits          []*Deposit
+	withdrawals       []*Withdrawal
+	transfersSent     []*Transfer
+	transfersReceived []*Transfer
+	subaccount        *Subaccount // nil for the master account
+}
+
+func newAccount() *account {
+	return &account{
+		balances:          make(map[string]*ledger),
+		orders:            make(map[string]*Order),
+		conditionalOrders: make(map[string]*conditionalOrder),
+		reserved:          make(map[string]decimal.Decimal),
+		addresses:         make(map[string]*Address),
+	}
+}
+
+func (a *account) ledger(currency string) *ledger {
+	l, ok := a.balances[currency]
+	if !ok {
+		l = &ledger{}
+		a.balances[currency] = l
+	}
+	return l
+}
+
+// restingOrder is an order sitting in the book. acct is nil for liquidity added by tests.
+type restingOrder struct {
+	id        string
+	acct      *account
+	direction string
+	rate      decimal.Decimal
+	remaining decimal.Decimal
+	seq       int64
+}
+
+// market holds the book and the trade history of a market.
+type market struct {
+	Market
+	bids     []*restingOrder // best (highest) first
+	asks     []*restingOrder // best (lowest) first
+	trades   []*Trade
+	sequence int64
+	candles  map[string][]Candle // by candle type and interval, oldest first
+}
+
+func (m *market) insert(o *restingOrder) {
+	if o.direction == buy {
+		m.bids = append(m.bids, o)
+		sort.SliceStable(m.bids, func(i, j int) bool {
+			if m.bids[i].rate.Equal(m.bids[j].rate) {
+				return m.bids[i].seq < m.bids[j].seq
+			}
+			return m.bids[i].rate.GreaterThan(m.bids[j].rate)
+		})
+	} else {
+		m.asks = append(m.asks, o)
+		sort.SliceStable(m.asks, func(i, j int) bool {
+			if m.asks[i].rate.Equal(m.asks[j].rate) {
+				return m.asks[i].seq < m.asks[j].seq
+			}
+			return m.asks[i].rate.LessThan(m.asks[j].rate)
+		})
+	}
+	m.sequence++
+}
+
+func (m *market) remove(id string) {
+	for i, o := range m.bids {
+		if o.id == id {
+			m.bids = append(m.bids[:i], m.bids[i+1:]...)
+			m.sequence++
+			return
+		}
+	}
+	for i, o := range m.asks {
+		if o.id == id {
+			m.asks = append(m.asks[:i], m.asks[i+1:]...)
+			m.sequence++
+			return
+		}
+	}
+}
+
+// opposite returns the side of the book an order of direction trades against.
+func (m *market) opposite(direction string) *[]*restingOrder {
+	if direction == buy {
+		return &m.asks
+	}
+	return &m.bids
+}
+
+// crosses reports whether rate is acceptable for a taker of direction with limit.
+func crosses(direction string, rate decimal.Decimal, limit *decimal.Decimal) bool {
+	if limit == nil {
+		return true
+	}
+	if direction == buy {
+		return rate.LessThanOrEqual(*limit)
+	}
+	return rate.GreaterThanOrEqual(*limit)
+}
+
+// aggregate returns the book side as price levels, limited to depth levels.
+func aggregate(orders []*restingOrder, depth int) []OrderBookEntry {
+	entries := []OrderBookEntry{}
+	for _, o := range orders {
+		n := len(entries)
+		if n > 0 && entries[n-1].Rate.Equal(o.rate) {
+			entries[n-1].Quantity = entries[n-1].Quantity.Add(o.remaining)
+			continue
+		}
+		if depth > 0 && n == depth {
+			break
+		}
+		entries = append(entries, OrderBookEntry{Quantity: o.remaining, Rate: o.rate})
+	}
+	return entries
+}
+
+// fill is a match between a taker and a resting order.
+type fill struct {
+	maker    *restingOrder
+	quantity decimal.Decimal
+	rate     decimal.Decimal
+}
+
+// plan walks the book for a taker without touching it. Quantity and quote bound the
+// taker; a nil bound is unlimited. Quote budgets include the commission.
+func (s *Server) plan(m *market, direction string, quantity, limit, quote *decimal.Decimal) []fill {
+	var fills []fill
+	remaining := quantity
+	budget := quote
+	for _, maker := range *m.opposite(direction) {
+		if !crosses(direction, maker.rate, limit) {
+			break
+		}
+		q := maker.remaining
+		if remaining != nil {
+			q = decimal.Min(q, *remaining)
+		}
+		if budget != nil {
+			unit := maker.rate.Mul(decimal.NewFromInt(1).Add(s.commissionRate))
+			q = decimal.Min(q, budget.Div(unit).Truncate(quantityPrecision))
+		}
+		if !q.IsPositive() {
+			break
+		}
+		fills = append(fills, fill{maker: maker, quantity: q, rate: maker.rate})
+		if remaining != nil {
+			r := remaining.Sub(q)
+			remaining = &r
+		}
+		if budget != nil {
+			b := budget.Sub(q.Mul(maker.rate).Mul(decimal.NewFromInt(1).Add(s.commissionRate)))
+			budget = &b
+		}
+	}
+	return fills
+}
+
+// cost returns what a buyer pays (or a seller receives before commission) for fills.
+func cost(fills []fill) decimal.Decimal {
+	total := decimal.Zero
+	for _, f := range fills {
+		total = total.Add(f.quantity.Mul(f.rate))
+	}
+	return total
+}
+
+func filledQuantity(fills []fill) decimal.Decimal {
+	total := decimal.Zero
+	for _, f := range fills {
+		total = total.Add(f.quantity)
+	}
+	return total
+}
+
+// settle books a fill for an account order: balances, order progress and execution.
+func (s *Server) settle(a *account, o *Order, m *market, quantity, rate decimal.Decimal, isTaker bool) {
+	now := s.now()
+	proceeds := quantity.Mul(rate)
+	commission := proceeds.Mul(s.commissionRate)
+
+	base := a.ledger(m.BaseCurrencySymbol)
+	quote := a.ledger(m.QuoteCurrencySymbol)
+	if o.Direction == buy {
+		paid := proceeds.Add(commission)
+		quote.total = quote.total.Sub(paid)
+		s.release(a, o, m.QuoteCurrencySymbol, paid)
+		base.total = base.total.Add(quantity)
+	} else {
+		base.total = base.total.Sub(quantity)
+		s.release(a, o, m.BaseCurrencySymbol, quantity)
+		quote.total = quote.total.Add(proceeds.Sub(commission))
+	}
+	base.updatedAt, quote.updatedAt = now, now
+
+	o.FillQuantity = o.FillQuantity.Add(quantity)
+	o.Proceeds = o.Proceeds.Add(proceeds)
+	o.Commission = o.Commission.Add(commission)
+	o.UpdatedAt = now
+	a.orderSequence++
+
+	a.executions = append(a.executions, &Execution{
+		ID:           uuid.New().String(),
+		MarketSymbol: m.Symbol,
+		ExecutedAt:   now,
+		Quantity:     quantity,
+		Rate:         rate,
+		OrderID:      o.ID,
+		Commission:   commission,
+		IsTaker:      isTaker,
+	})
+}
+
+// reserve locks amount of currency for an order.
+func (s *Server) reserve(a *account, o *Order, currency string, amount decimal.Decimal) {
+	l := a.ledger(currency)
+	l.reserved = l.reserved.Add(amount)
+	a.reserved[o.ID] = a.reserved[o.ID].Add(amount)
+}
+
+// release unlocks up to amount of the reservation of an order.
+func (s *Server) release(a *account, o *Order, currency string, amount decimal.Decimal) {
+	amount = decimal.Min(amount, a.reserved[o.ID])
+	l := a.ledger(currency)
+	l.reserved = l.reserved.Sub(amount)
+	a.reserved[o.ID] = a.reserved[o.ID].Sub(amount)
+}
+
+// reservedCurrency returns the currency an order locks.
+func reservedCurrency(m *market, direction string) string {
+	if direction == buy {
+		return m.QuoteCurrencySymbol
+	}
+	return m.BaseCurrencySymbol
+}
+
+// trade executes fills against the book for a taker, which is an account order or
+// liquidity when o is nil.
+func (s *Server) trade(a *account, o *Order, m *market, direction string, fills []fill) {
+	now := s.now()
+	book := m.opposite(direction)
+	for _, f := range fills {
+		f.maker.remaining = f.maker.remaining.Sub(f.quantity)
+		if f.maker.acct != nil {
+			maker := f.maker.acct.orders[f.maker.id]
+			s.settle(f.maker.acct, maker, m, f.quantity, f.rate, false)
+			if !f.maker.remaining.IsPositive() {
+				s.close(f.maker.acct, maker)
+			}
+		}
+		if o != nil {
+			s.settle(a, o, m, f.quantity, f.rate, true)
+		}
+		m.trades = append(m.trades, &Trade{
+			ID:         uuid.New().String(),
+			ExecutedAt: now,
+			Quantity:   f.quantity,
+			Rate:       f.rate,
+			TakerSide:  direction,
+		})
+	}
+	kept := (*book)[:0]
+	for _, maker := range *book {
+		if maker.remaining.IsPositive() {
+			kept = append(kept, maker)
+		}
+	}
+	*book = kept
+	if len(fills) > 0 {
+		m.sequence++
+	}
+}
+
+// close closes an order and releases what it still locks.
+func (s *Server) close(a *account, o *Order) {
+	now := s.now()
+	if m, ok := s.markets[o.MarketSymbol]; ok {
+		m.remove(o.ID)
+		s.release(a, o, reservedCurrency(m, o.Direction), a.reserved[o.ID])
+	}
+	delete(a.reserved, o.ID)
+	o.Status = statusClosed
+	o.UpdatedAt = now
+	o.ClosedAt = &now
+	a.orderSequence++
+	s.cancelLinked(a, linkedOrder, o.ID)
+}
+
+// cancelOrder cancels an open order of an account.
+func (s *Server) cancelOrder(a *account, id string) (*Order, *httpError) {
+	o, ok := a.orders[id]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+	}
+	if o.Status != statusOpen {
+		return nil, newHTTPError(http.StatusConflict, "ORDER_NOT_OPEN")
+	}
+	s.close(a, o)
+	return o, nil
+}
+
+// placeOrder validates, matches and books a new order for an account.
+func (s *Server) placeOrder(a *account, req NewOrder) (*Order, *httpError) {
+	m, ok := s.markets[req.MarketSymbol]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "MARKET_DOES_NOT_EXIST")
+	}
+	if m.Status != "ONLINE" {
+		return nil, newHTTPError(http.StatusBadRequest, "MARKET_OFFLINE")
+	}
+	if req.Direction != buy && req.Direction != sell {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_DIRECTION")
+	}
+	if req.ClientOrderID != "" {
+		for _, id := range a.orderIDs {
+			if a.orders[id].ClientOrderID == req.ClientOrderID {
+				return nil, newHTTPError(http.StatusConflict, "DUPLICATE_CLIENT_ORDER_ID")
+			}
+		}
+	}
+
+	quantity, limit, ceiling := positive(req.Quantity), positive(req.Limit), positive(req.Ceiling)
+	switch req.Type {
+	case typeMarket:
+		limit, ceiling = nil, nil
+		if quantity == nil {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_QUANTITY")
+		}
+	case typeLimit:
+		ceiling = nil
+		if quantity == nil || limit == nil {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_ORDER")
+		}
+	case typeCeilingLimit, typeCeilingMarket:
+		quantity = nil
+		if req.Type == typeCeilingMarket {
+			limit = nil
+		}
+		if req.Direction != buy || ceiling == nil || (req.Type == typeCeilingLimit && limit == nil) {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_ORDER")
+		}
+	default:
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_ORDER_TYPE")
+	}
+	if quantity != nil && quantity.LessThan(m.MinTradeSize) {
+		return nil, newHTTPError(http.StatusBadRequest, "MIN_TRADE_REQUIREMENT_NOT_MET")
+	}
+	if limit != nil && !limit.Equal(limit.Truncate(m.Precision)) {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_LIMIT_PRECISION")
+	}
+
+	fills := s.plan(m, req.Direction, quantity, limit, ceiling)
+	filled := filledQuantity(fills)
+	rests := req.Type == typeLimit && (req.TimeInForce == goodTilCancelled || req.TimeInForce == postOnlyGoodTilCancelled)
+
+	switch req.TimeInForce {
+	case postOnlyGoodTilCancelled:
+		if len(fills) > 0 {
+			return nil, newHTTPError(http.StatusBadRequest, "POST_ONLY_ORDER_WOULD_TAKE")
+		}
+	case fillOrKill:
+		if quantity != nil && filled.LessThan(*quantity) {
+			fills = nil
+		}
+	case goodTilCancelled, immediateOrCancel:
+	default:
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_TIME_IN_FORCE")
+	}
+
+	// Funds locked by the order
+	fee := decimal.NewFromInt(1).Add(s.commissionRate)
+	var locked decimal.Decimal
+	switch {
+	case req.Direction == sell:
+		locked = *quantity
+	case ceiling != nil:
+		locked = *ceiling
+	case rests:
+		locked = quantity.Mul(*limit).Mul(fee)
+	default:
+		locked = cost(fills).Mul(fee)
+	}
+	currency := reservedCurrency(m, req.Direction)
+	if a.ledger(currency).available().LessThan(locked) {
+		return nil, newHTTPError(http.StatusBadRequest, "INSUFFICIENT_FUNDS")
+	}
+
+	now := s.now()
+	o := &Order{
+		ID:            uuid.New().String(),
+		MarketSymbol:  m.Symbol,
+		Direction:     req.Direction,
+		Type:          req.Type,
+		Quantity:      quantity,
+		Limit:         limit,
+		Ceiling:       ceiling,
+		TimeInForce:   req.TimeInForce,
+		ClientOrderID: req.ClientOrderID,
+		Status:        statusOpen,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	a.orders[o.ID] = o
+	a.orderIDs = append(a.orderIDs, o.ID)
+	a.orderSequence++
+	s.reserve(a, o, currency, locked)
+
+	s.trade(a, o, m, req.Direction, fills)
+
+	if rests && filled.LessThan(*quantity) {
+		s.seq++
+		m.insert(&restingOrder{
+			id:        o.ID,
+			acct:      a,
+			direction: o.Direction,
+			rate:      *limit,
+			remaining: quantity.Sub(filled),
+			seq:       s.seq,
+		})
+	} else {
+		s.close(a, o)
+	}
+	s.triggerConditionalOrders(m)
+	return o, nil
+}
+
+// addLiquidity adds an order owned by nobody, matching it first against the book.
+func (s *Server) addLiquidity(m *market, direction string, rate, quantity decimal.Decimal) {
+	fills := s.plan(m, direction, &quantity, &rate, nil)
+	s.trade(nil, nil, m, direction, fills)
+	if remaining := quantity.Sub(filledQuantity(fills)); remaining.IsPositive() {
+		s.seq++
+		m.insert(&restingOrder{
+			id:        uuid.New().String(),
+			direction: direction,
+			rate:      rate,
+			remaining: remaining,
+			seq:       s.seq,
+		})
+	}
+	s.triggerConditionalOrders(m)
+}
+
+// positive returns d if it is set and strictly positive.
+func positive(d *decimal.Decimal) *decimal.Decimal {
+	if d == nil || !d.IsPositive() {
+		return nil
+	}
+	return d
+}
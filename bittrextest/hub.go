@@ -0,0 +1,435 @@
+package bittrextest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Websocket message names pushed by the hub
+const (
+	MethodOrderBook   = "orderBook"
+	MethodTicker      = "ticker"
+	MethodTrade       = "trade"
+	MethodOrder       = "order"
+	MethodBalance     = "balance"
+	MethodHeartbeat   = "heartbeat"
+	MethodAuthExpired = "authenticationExpiring"
+)
+
+// OrderBookUpdate mirrors the v3 orderBook websocket message.
+type OrderBookUpdate struct {
+	MarketSymbol string           `json:"marketSymbol"`
+	Depth        int              `json:"depth"`
+	Sequence     int64            `json:"sequence"`
+	BidDeltas    []OrderBookEntry `json:"bidDeltas"`
+	AskDeltas    []OrderBookEntry `json:"askDeltas"`
+}
+
+// TradeUpdate mirrors the v3 trade websocket message.
+type TradeUpdate struct {
+	Sequence     int64   `json:"sequence"`
+	MarketSymbol string  `json:"marketSymbol"`
+	Deltas       []Trade `json:"deltas"`
+}
+
+// OrderUpdate mirrors the v3 order websocket message.
+type OrderUpdate struct {
+	AccountID string `json:"accountId"`
+	Sequence  int64  `json:"sequence"`
+	Delta     Order  `json:"delta"`
+}
+
+// BalanceUpdate mirrors the v3 balance websocket message.
+type BalanceUpdate struct {
+	AccountID string  `json:"accountId"`
+	Sequence  int64   `json:"sequence"`
+	Delta     Balance `json:"delta"`
+}
+
+// hubResult is the answer of Subscribe, Unsubscribe and Authenticate.
+type hubResult struct {
+	Success   bool        `json:"Success"`
+	ErrorCode interface{} `json:"ErrorCode"`
+}
+
+// Hub is a fake of the Bittrex v3 SignalR websocket hub.
+//
+// The bittrex package always dials websockets over TLS: give it the configuration trusting
+// the certificate of the hub with bittrex.WithWebsocketTLSConfig(hub.TLSConfig()).
+type Hub struct {
+	srv       *httptest.Server
+	apiKey    string
+	apiSecret string
+
+	closeOnce sync.Once
+
+	mu          sync.Mutex
+	conns       map[*hubConn]bool
+	calls       []HubCall
+	subscribers chan struct{} // closed and replaced on every change of the subscriptions
+}
+
+// HubCall is a hub method invoked by a client.
+type HubCall struct {
+	Method    string
+	Arguments []json.RawMessage
+}
+
+type hubConn struct {
+	ws            *websocket.Conn
+	hub           string
+	writeMu       sync.Mutex
+	authenticated bool
+	channels      map[string]bool
+}
+
+// NewHub starts a fake hub accepting Authenticate calls signed with apiKey and apiSecret.
+func NewHub(apiKey, apiSecret string) *Hub {
+	h := &Hub{
+		apiKey:      apiKey,
+		apiSecret:   apiSecret,
+		conns:       make(map[*hubConn]bool),
+		subscribers: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signalr/negotiate", h.negotiate)
+	mux.HandleFunc("/signalr/connect", h.connect)
+	h.srv = httptest.NewTLSServer(mux)
+	return h
+}
+
+// Host returns the address of the hub, to be given to bittrex.WithWebsocket.
+func (h *Hub) Host() string {
+	return strings.TrimPrefix(h.srv.URL, "https://")
+}
+
+// TLSConfig returns a TLS configuration trusting the certificate of the hub, to be given
+// to bittrex.WithWebsocketTLSConfig.
+func (h *Hub) TLSConfig() *tls.Config {
+	return h.srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+}
+
+// Close disconnects every client and shuts down the hub. It may be called more than once.
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() {
+		h.Disconnect()
+		h.srv.Close()
+	})
+}
+
+// Calls returns the hub methods invoked so far.
+func (h *Hub) Calls() []HubCall {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]HubCall(nil), h.calls...)
+}
+
+// Connections returns the number of connected clients.
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// WaitSubscribed blocks until a client is subscribed to channel, or timeout elapses.
+func (h *Hub) WaitSubscribed(channel string, timeout time.Duration) error {
+	deadline := time.After(timeout)
+	for {
+		h.mu.Lock()
+		changed := h.subscribers
+		subscribed := h.subscribed(channel)
+		h.mu.Unlock()
+		if subscribed {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return fmt.Errorf("bittrextest: no subscription to %s after %s", channel, timeout)
+		}
+	}
+}
+
+func (h *Hub) subscribed(channel string) bool {
+	for c := range h.conns {
+		if c.channels[channel] {
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes up WaitSubscribed callers. h.mu must be held.
+func (h *Hub) notify() {
+	close(h.subscribers)
+	h.subscribers = make(chan struct{})
+}
+
+// Disconnect drops every client connection.
+func (h *Hub) Disconnect() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.conns {
+		c.ws.Close()
+		delete(h.conns, c)
+	}
+	h.notify()
+}
+
+// ExpireAuth sends authenticationExpiring to authenticated clients and revokes their
+// authentication: private messages are no longer delivered until they authenticate again.
+func (h *Hub) ExpireAuth() {
+	h.mu.Lock()
+	var conns []*hubConn
+	for c := range h.conns {
+		if c.authenticated {
+			c.authenticated = false
+			conns = append(conns, c)
+		}
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.push(MethodAuthExpired, nil)
+	}
+}
+
+// Push sends a message to the clients subscribed to channel. Private channels
+// (order, balance) only reach authenticated clients.
+func (h *Hub) Push(channel, method string, payload interface{}) error {
+	data, err := encodePayload(payload)
+	if err != nil {
+		return err
+	}
+	private := isPrivate(channel)
+	h.mu.Lock()
+	var conns []*hubConn
+	for c := range h.conns {
+		if c.channels[channel] && (!private || c.authenticated) {
+			conns = append(conns, c)
+		}
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.push(method, []string{data})
+	}
+	return nil
+}
+
+// PushOrderBook sends an order book delta to orderbook_{market}_{depth} subscribers.
+func (h *Hub) PushOrderBook(u OrderBookUpdate) error {
+	return h.Push(fmt.Sprintf("orderbook_%s_%d", u.MarketSymbol, u.Depth), MethodOrderBook, u)
+}
+
+// PushTicker sends a ticker to ticker_{market} subscribers.
+func (h *Hub) PushTicker(t Ticker) error {
+	return h.Push("ticker_"+t.Symbol, MethodTicker, t)
+}
+
+// PushTrade sends trades to trade_{market} subscribers.
+func (h *Hub) PushTrade(u TradeUpdate) error {
+	return h.Push("trade_"+u.MarketSymbol, MethodTrade, u)
+}
+
+// PushOrder sends an order delta to order subscribers.
+func (h *Hub) PushOrder(u OrderUpdate) error {
+	return h.Push("order", MethodOrder, u)
+}
+
+// PushBalance sends a balance delta to balance subscribers.
+func (h *Hub) PushBalance(u BalanceUpdate) error {
+	return h.Push("balance", MethodBalance, u)
+}
+
+// Heartbeat sends a heartbeat to heartbeat subscribers.
+func (h *Hub) Heartbeat() {
+	h.mu.Lock()
+	var conns []*hubConn
+	for c := range h.conns {
+		if c.channels["heartbeat"] {
+			conns = append(conns, c)
+		}
+	}
+	h.mu.Unlock()
+	for _, c := range conns {
+		c.push(MethodHeartbeat, nil)
+	}
+}
+
+func (h *Hub) negotiate(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Url":                     "/signalr",
+		"ConnectionToken":         "token",
+		"ConnectionId":            "connection",
+		"KeepAliveTimeout":        20.0,
+		"DisconnectTimeout":       30.0,
+		"ConnectionTimeout":       110.0,
+		"TryWebSockets":           true,
+		"ProtocolVersion":         "1.5",
+		"TransportConnectTimeout": 5.0,
+		"LongPollDelay":           0.0,
+	})
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+func (h *Hub) connect(w http.ResponseWriter, r *http.Request) {
+	var hubs []struct {
+		Name string `json:"Name"`
+	}
+	_ = json.Unmarshal([]byte(r.URL.Query().Get("connectionData")), &hubs)
+	if len(hubs) != 1 {
+		http.Error(w, "expected one hub", http.StatusBadRequest)
+		return
+	}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &hubConn{ws: ws, hub: hubs[0].Name, channels: make(map[string]bool)}
+	h.mu.Lock()
+	h.conns[c] = true
+	h.mu.Unlock()
+
+	c.write(map[string]interface{}{"C": "init", "S": 1, "M": []interface{}{}})
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		var call struct {
+			Hub       string            `json:"H"`
+			Method    string            `json:"M"`
+			Arguments []json.RawMessage `json:"A"`
+			ID        int               `json:"I"`
+		}
+		if err := json.Unmarshal(data, &call); err != nil {
+			continue
+		}
+		result, err := h.invoke(c, call.Method, call.Arguments)
+		response := map[string]interface{}{"I": fmt.Sprintf("%d", call.ID)}
+		if err != nil {
+			response["E"] = err.Error()
+		} else {
+			response["R"] = result
+		}
+		c.write(response)
+	}
+
+	h.mu.Lock()
+	delete(h.conns, c)
+	h.notify()
+	h.mu.Unlock()
+}
+
+// invoke runs a hub method called by a client.
+func (h *Hub) invoke(c *hubConn, method string, args []json.RawMessage) (interface{}, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls = append(h.calls, HubCall{Method: method, Arguments: args})
+
+	switch method {
+	case "Authenticate":
+		var apiKey, uuid, signature string
+		var timestamp int64
+		if len(args) != 4 ||
+			json.Unmarshal(args[0], &apiKey) != nil ||
+			json.Unmarshal(args[1], &timestamp) != nil ||
+			json.Unmarshal(args[2], &uuid) != nil ||
+			json.Unmarshal(args[3], &signature) != nil {
+			return nil, errors.New("invalid Authenticate arguments")
+		}
+		mac := hmac.New(sha512.New, []byte(h.apiSecret))
+		mac.Write([]byte(fmt.Sprintf("%d%s", timestamp, uuid)))
+		if apiKey != h.apiKey {
+			return hubResult{ErrorCode: "APIKEY_INVALID"}, nil
+		}
+		if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+			return hubResult{ErrorCode: "INVALID_SIGNATURE"}, nil
+		}
+		c.authenticated = true
+		return hubResult{Success: true}, nil
+
+	case "Subscribe", "Unsubscribe":
+		var channels []string
+		if len(args) != 1 || json.Unmarshal(args[0], &channels) != nil {
+			return nil, errors.New("invalid " + method + " arguments")
+		}
+		results := make([]hubResult, len(channels))
+		for i, channel := range channels {
+			switch {
+			case method == "Unsubscribe":
+				delete(c.channels, channel)
+				results[i].Success = true
+			case isPrivate(channel) && !c.authenticated:
+				results[i].ErrorCode = "UNAUTHORIZED"
+			default:
+				c.channels[channel] = true
+				results[i].Success = true
+			}
+		}
+		h.notify()
+		return results, nil
+	}
+	return nil, fmt.Errorf("unknown hub method %s", method)
+}
+
+// isPrivate reports whether channel requires an authenticated connection.
+func isPrivate(channel string) bool {
+	switch channel {
+	case "order", "balance", "execution", "conditional_order":
+		return true
+	}
+	return false
+}
+
+func (c *hubConn) write(v interface{}) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.ws.WriteJSON(v)
+}
+
+// push invokes a client method, one call per frame as SignalR does.
+func (c *hubConn) push(method string, args []string) {
+	if args == nil {
+		args = []string{}
+	}
+	c.write(map[string]interface{}{
+		"C": "cursor",
+		"M": []interface{}{map[string]interface{}{"H": c.hub, "M": method, "A": args}},
+	})
+}
+
+// encodePayload compresses payload as Bittrex does: JSON, raw deflate, base64.
+func encodePayload(payload interface{}) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,129 @@
+package bittrextest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	bittrex "github.com/mountalpha/basecamp-bittrex-connector"
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func newTestHub(t *testing.T) (*bittrextest.Hub, *bittrex.Bittrex) {
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+	bt := bittrex.New("key", "secret", bittrex.WithWebsocket(hub.Host(), bittrex.WS_HUB), bittrex.WithWebsocketTLSConfig(hub.TLSConfig()))
+	return hub, bt
+}
+
+func TestHubTicker(t *testing.T) {
+	hub, bt := newTestHub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan bittrex.Ticker, 1)
+	errs := make(chan error, 1)
+	go func() { errs <- bt.SubscribeTickerUpdatesCtx(ctx, "LTC-BTC", ch) }()
+
+	assert.Nil(t, hub.WaitSubscribed("ticker_LTC-BTC", 5*time.Second))
+	assert.Nil(t, hub.PushTicker(bittrextest.Ticker{Symbol: "LTC-BTC", BidRate: d("0.004")}))
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ticker received")
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled))
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not stop")
+	}
+}
+
+func TestHubBalanceAuthentication(t *testing.T) {
+	hub, bt := newTestHub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan bittrex.BalanceUpdate, 1)
+	go bt.SubscribeBalanceUpdatesCtx(ctx, ch)
+
+	assert.Nil(t, hub.WaitSubscribed("balance", 5*time.Second))
+	assert.Nil(t, hub.PushBalance(bittrextest.BalanceUpdate{
+		AccountID: "account",
+		Sequence:  1,
+		Delta:     bittrextest.Balance{CurrencySymbol: "BTC", Total: d("1.5"), Available: d("1")},
+	}))
+	select {
+	case u := <-ch:
+		assert.Equal(t, 1, u.Sequence)
+		assert.Equal(t, "BTC", u.Delta.CurrencySymbol)
+		assert.True(t, d("1.5").Equal(u.Delta.Total))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no balance received")
+	}
+
+	calls := hub.Calls()
+	if assert.True(t, len(calls) >= 2) {
+		assert.Equal(t, "Authenticate", calls[0].Method)
+		assert.Equal(t, "Subscribe", calls[1].Method)
+	}
+
+	// Private messages stop once the authentication expired
+	hub.ExpireAuth()
+	assert.Nil(t, hub.PushBalance(bittrextest.BalanceUpdate{Sequence: 2}))
+	select {
+	case u := <-ch:
+		t.Fatalf("unexpected balance %v", u)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHubDisconnect(t *testing.T) {
+	hub, bt := newTestHub(t)
+
+	ch := make(chan bittrex.OrderBook, 1)
+	errs := make(chan error, 1)
+	go func() { errs <- bt.SubscribeOrderbookUpdates("LTC-BTC", ch, make(chan bool)) }()
+
+	assert.Nil(t, hub.WaitSubscribed("orderbook_LTC-BTC_25", 5*time.Second))
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{MarketSymbol: "LTC-BTC", Depth: 25, Sequence: 1}))
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order book received")
+	}
+
+	hub.Disconnect()
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "client.DisconnectedChannel")
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnection not detected")
+	}
+}
+
+func TestHubLeavesDefaultClientsAlone(t *testing.T) {
+	hub, bt := newTestHub(t)
+	if config := http.DefaultTransport.(*http.Transport).TLSClientConfig; config != nil {
+		assert.Nil(t, config.RootCAs)
+	}
+	assert.Nil(t, websocket.DefaultDialer.TLSClientConfig)
+
+	// Only clients given the configuration of the hub trust it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s, err := bt.OpenStream(ctx)
+	if assert.Nil(t, err) {
+		s.Close()
+	}
+	_, err = bittrex.New("key", "secret", bittrex.WithWebsocket(hub.Host(), bittrex.WS_HUB)).OpenStream(ctx)
+	assert.Error(t, err)
+}
@@ -0,0 +1,1175 @@
+// Package bittrextest provides an in-process fake of the Bittrex v3 REST API,
+// for offline and deterministic tests of code built on the bittrex package.
+//
+//	srv := bittrextest.NewServer("key", "secret")
+//	defer srv.Close()
+//	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8, Status: "ONLINE"})
+//	srv.SetBalance("BTC", decimal.NewFromInt(1))
+//	srv.AddLiquidity("LTC-BTC", "SELL", decimal.RequireFromString("0.004"), decimal.NewFromInt(10))
+//
+//	bt := bittrex.New("key", "secret", bittrex.WithBaseURL(srv.URL()))
+//
+// Requests to private endpoints must be signed exactly as the bittrex client does.
+package bittrextest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCommissionRate is the commission charged on every fill, in quote currency
+var DefaultCommissionRate = decimal.RequireFromString("0.0025")
+
+// httpError is an error answered to the client.
+type httpError struct {
+	status int
+	body   apiError
+}
+
+func newHTTPError(status int, code string) *httpError {
+	return &httpError{status: status, body: apiError{Code: code}}
+}
+
+// Server is a fake Bittrex v3 REST API backed by a matching engine and a balance ledger.
+type Server struct {
+	srv *httptest.Server
+
+	mu             sync.Mutex
+	apiKey         string
+	apiSecret      string
+	now            func() time.Time
+	commissionRate decimal.Decimal
+	accountID      string
+	volume         AccountVolume
+	currencies     map[string]*Currency
+	markets        map[string]*market
+	account        *account // master account
+	subaccounts    map[string]*account
+	subaccountIDs  []string // creation order
+	seq            int64
+	failures       []*httpError
+	requests       []*http.Request
+}
+
+// NewServer starts a fake API accepting requests signed with apiKey and apiSecret.
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		apiKey:         apiKey,
+		apiSecret:      apiSecret,
+		now:            func() time.Time { return time.Now().UTC() },
+		commissionRate: DefaultCommissionRate,
+		accountID:      uuid.New().String(),
+		currencies:     make(map[string]*Currency),
+		markets:        make(map[string]*market),
+		account:        newAccount(),
+		subaccounts:    make(map[string]*account),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the fake API, to be given to bittrex.WithBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL + "/v3"
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// SetClock sets the time source used for timestamps.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// SetCommissionRate sets the commission charged on every fill.
+func (s *Server) SetCommissionRate(rate decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commissionRate = rate
+}
+
+// SetAccountVolume sets the 30-day trading volume of the account, in USD.
+func (s *Server) SetAccountVolume(volume decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volume = AccountVolume{Updated: s.now(), Volume30days: volume}
+}
+
+// AddCurrency adds or replaces a currency.
+func (s *Server) AddCurrency(c Currency) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.Status == "" {
+		c.Status = "ONLINE"
+	}
+	s.currencies[c.Symbol] = &c
+}
+
+// AddMarket adds or replaces a market, along with its currencies if unknown.
+func (s *Server) AddMarket(m Market) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.Status == "" {
+		m.Status = "ONLINE"
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = s.now()
+	}
+	if existing, ok := s.markets[m.Symbol]; ok {
+		existing.Market = m
+	} else {
+		s.markets[m.Symbol] = &market{Market: m, candles: make(map[string][]Candle)}
+	}
+	for _, symbol := range []string{m.BaseCurrencySymbol, m.QuoteCurrencySymbol} {
+		if _, ok := s.currencies[symbol]; !ok {
+			s.currencies[symbol] = &Currency{Symbol: symbol, Name: symbol, CoinType: "BITCOIN", Status: "ONLINE"}
+		}
+	}
+}
+
+// AddCandles adds candles of candleType (TRADE, MIDPOINT) and interval (MINUTE_1, MINUTE_5,
+// HOUR_1, DAY_1) to a market. Candles are served by the recent and historical endpoints
+// according to their StartsAt.
+func (s *Server) AddCandles(symbol, candleType, interval string, candles ...Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.markets[symbol]
+	if !ok {
+		panic("bittrextest: unknown market " + symbol)
+	}
+	key := candleType + "/" + interval
+	m.candles[key] = append(m.candles[key], candles...)
+	sort.SliceStable(m.candles[key], func(i, j int) bool { return m.candles[key][i].StartsAt.Before(m.candles[key][j].StartsAt) })
+}
+
+// SetMarketStatus changes the status (ONLINE, OFFLINE) and notice of a market.
+func (s *Server) SetMarketStatus(symbol, status, notice string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m, ok := s.markets[symbol]; ok {
+		m.Status = status
+		m.Notice = notice
+	}
+}
+
+// SetBalance sets the total balance of a currency. Funds locked by open orders are kept.
+func (s *Server) SetBalance(currency string, total decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.account.ledger(currency)
+	l.total = total
+	l.updatedAt = s.now()
+}
+
+// Balance returns the balance of a currency.
+func (s *Server) Balance(currency string) Balance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance(s.account, currency)
+}
+
+// AddLiquidity places an order owned by nobody in a market. It first trades against
+// the resting orders it crosses, which is how tests fill the orders of the account.
+func (s *Server) AddLiquidity(symbol, direction string, rate, quantity decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.markets[symbol]
+	if !ok {
+		panic("bittrextest: unknown market " + symbol)
+	}
+	s.addLiquidity(m, direction, rate, quantity)
+}
+
+// Order returns a copy of an order of the account.
+func (s *Server) Order(id string) (Order, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.account.orders[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// OrderSequence returns the sequence of the last order change of the account, which
+// GET orders/open reports in its Sequence header. Order deltas pushed to the hub follow it.
+func (s *Server) OrderSequence() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.account.orderSequence
+}
+
+// ConditionalOrder returns a copy of a conditional order of the account.
+func (s *Server) ConditionalOrder(id string) (ConditionalOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.account.conditionalOrders[id]
+	if !ok {
+		return ConditionalOrder{}, false
+	}
+	return c.ConditionalOrder, true
+}
+
+// Executions returns the fills of the account, oldest first.
+func (s *Server) Executions() []Execution {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	executions := make([]Execution, len(s.account.executions))
+	for i, e := range s.account.executions {
+		executions[i] = *e
+	}
+	return executions
+}
+
+// AddDeposit records a deposit and credits the balance if it is COMPLETED.
+func (s *Server) AddDeposit(d Deposit) Deposit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	if d.Status == "" {
+		d.Status = "COMPLETED"
+	}
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = s.now()
+	}
+	if d.Status == "COMPLETED" {
+		if d.CompletedAt == nil {
+			d.CompletedAt = &d.UpdatedAt
+		}
+		l := s.account.ledger(d.CurrencySymbol)
+		l.total = l.total.Add(d.Quantity)
+		l.updatedAt = d.UpdatedAt
+	}
+	s.account.deposits = append(s.account.deposits, &d)
+	return d
+}
+
+// CompleteWithdrawal marks a withdrawal as COMPLETED with the given on chain transaction id.
+func (s *Server) CompleteWithdrawal(id, txID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, w := range s.account.withdrawals {
+		if w.ID == id {
+			now := s.now()
+			w.Status = "COMPLETED"
+			w.TxID = txID
+			w.CompletedAt = &now
+		}
+	}
+}
+
+// FailNext makes the next request fail with status and Bittrex error code.
+// Calls stack up: each one fails one more request.
+func (s *Server) FailNext(status int, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, newHTTPError(status, code))
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		writeJSON(w, f.status, f.body)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, "/v3/") {
+		writeJSON(w, http.StatusNotFound, apiError{Code: "NOT_FOUND"})
+		return
+	}
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v3/"), "/"), "/")
+
+	var result interface{}
+	var herr *httpError
+	switch path[0] {
+	case "ping":
+		result = map[string]int64{"serverTime": s.now().UnixNano() / int64(time.Millisecond)}
+	case "currencies":
+		result, herr = s.currenciesRoute(r, path)
+	case "markets":
+		result, herr = s.marketsRoute(w, r, path)
+	default:
+		var a *account
+		if a, herr = s.authenticate(r, body); herr == nil {
+			result, herr = s.privateRoute(w, a, r, path, body)
+		}
+	}
+	if herr != nil {
+		writeJSON(w, herr.status, herr.body)
+		return
+	}
+	status := http.StatusOK
+	if r.Method == "POST" {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, result)
+}
+
+// authenticate checks the headers signed by the client and returns the account they grant.
+func (s *Server) authenticate(r *http.Request, body []byte) (*account, *httpError) {
+	if r.Header.Get("Api-Key") != s.apiKey {
+		return nil, newHTTPError(http.StatusUnauthorized, "APIKEY_INVALID")
+	}
+	contentSum := sha512.Sum512(body)
+	contentHash := hex.EncodeToString(contentSum[:])
+	if r.Header.Get("Api-Content-Hash") != contentHash {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_CONTENT_HASH")
+	}
+	timestamp := r.Header.Get("Api-Timestamp")
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_TIMESTAMP")
+	}
+	uri := "http://" + r.Host + r.URL.RequestURI()
+	subaccountID := r.Header.Get("Api-Subaccount-Id")
+	mac := hmac.New(sha512.New, []byte(s.apiSecret))
+	mac.Write([]byte(timestamp + uri + r.Method + contentHash + subaccountID))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("Api-Signature"))) {
+		return nil, newHTTPError(http.StatusUnauthorized, "INVALID_SIGNATURE")
+	}
+	if subaccountID == "" {
+		return s.account, nil
+	}
+	a, ok := s.subaccounts[subaccountID]
+	if !ok {
+		return nil, newHTTPError(http.StatusForbidden, "SUBACCOUNT_NOT_FOUND")
+	}
+	return a, nil
+}
+
+// accounts returns the master account followed by the subaccounts, in creation order.
+func (s *Server) accounts() []*account {
+	accounts := []*account{s.account}
+	for _, id := range s.subaccountIDs {
+		accounts = append(accounts, s.subaccounts[id])
+	}
+	return accounts
+}
+
+func (s *Server) currenciesRoute(r *http.Request, path []string) (interface{}, *httpError) {
+	if r.Method != "GET" {
+		return nil, newHTTPError(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED")
+	}
+	if len(path) == 1 {
+		currencies := make([]*Currency, 0, len(s.currencies))
+		for _, c := range s.currencies {
+			currencies = append(currencies, c)
+		}
+		sort.Slice(currencies, func(i, j int) bool { return currencies[i].Symbol < currencies[j].Symbol })
+		return currencies, nil
+	}
+	c, ok := s.currencies[strings.ToUpper(path[1])]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "CURRENCY_DOES_NOT_EXIST")
+	}
+	return c, nil
+}
+
+func (s *Server) marketsRoute(w http.ResponseWriter, r *http.Request, path []string) (interface{}, *httpError) {
+	if r.Method != "GET" {
+		return nil, newHTTPError(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED")
+	}
+	if len(path) == 1 {
+		markets := []Market{}
+		for _, m := range s.sortedMarkets() {
+			markets = append(markets, m.Market)
+		}
+		return markets, nil
+	}
+	switch path[1] {
+	case "tickers":
+		tickers := []Ticker{}
+		for _, m := range s.sortedMarkets() {
+			tickers = append(tickers, s.ticker(m))
+		}
+		return tickers, nil
+	case "summaries":
+		summaries := []MarketSummary{}
+		for _, m := range s.sortedMarkets() {
+			summaries = append(summaries, s.summary(m))
+		}
+		return summaries, nil
+	}
+
+	m, ok := s.markets[path[1]]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "MARKET_DOES_NOT_EXIST")
+	}
+	if len(path) == 2 {
+		return m.Market, nil
+	}
+	switch path[2] {
+	case "ticker":
+		return s.ticker(m), nil
+	case "summary":
+		return s.summary(m), nil
+	case "orderbook":
+		depth, _ := strconv.Atoi(r.URL.Query().Get("depth"))
+		if depth == 0 {
+			depth = 25
+		}
+		if depth != 1 && depth != 25 && depth != 500 {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_DEPTH")
+		}
+		w.Header().Set("Sequence", strconv.FormatInt(m.sequence, 10))
+		return OrderBook{Bid: aggregate(m.bids, depth), Ask: aggregate(m.asks, depth)}, nil
+	case "candles":
+		return s.candles(m, path[3:])
+	case "trades":
+		trades := []*Trade{}
+		for i := len(m.trades) - 1; i >= 0 && len(trades) < 100; i-- {
+			trades = append(trades, m.trades[i])
+		}
+		return trades, nil
+	}
+	return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+}
+
+// candleWindows are the periods covered by the recent candles endpoint.
+var candleWindows = map[string]time.Duration{
+	"MINUTE_1": 24 * time.Hour,
+	"MINUTE_5": 24 * time.Hour,
+	"HOUR_1":   31 * 24 * time.Hour,
+	"DAY_1":    366 * 24 * time.Hour,
+}
+
+// candles serves {type}/{interval}/recent and {type}/{interval}/historical/{year}[/{month}[/{day}]].
+func (s *Server) candles(m *market, path []string) (interface{}, *httpError) {
+	if len(path) < 3 {
+		return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+	}
+	candleType, interval := path[0], path[1]
+	window, ok := candleWindows[interval]
+	if !ok || (candleType != "TRADE" && candleType != "MIDPOINT") {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_CANDLE_INTERVAL")
+	}
+
+	var from, to time.Time
+	switch {
+	case path[2] == "recent" && len(path) == 3:
+		to = s.now()
+		from = to.Add(-window)
+	case path[2] == "historical":
+		// Minute candles are served by day, hourly ones by month, daily ones by year
+		date := path[3:]
+		expected := map[string]int{"MINUTE_1": 3, "MINUTE_5": 3, "HOUR_1": 2, "DAY_1": 1}[interval]
+		if len(date) != expected {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_DATE")
+		}
+		parts := []int{0, 1, 1}
+		for i, v := range date {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, newHTTPError(http.StatusBadRequest, "INVALID_DATE")
+			}
+			parts[i] = n
+		}
+		from = time.Date(parts[0], time.Month(parts[1]), parts[2], 0, 0, 0, 0, time.UTC)
+		switch len(date) {
+		case 1:
+			to = from.AddDate(1, 0, 0)
+		case 2:
+			to = from.AddDate(0, 1, 0)
+		default:
+			to = from.AddDate(0, 0, 1)
+		}
+	default:
+		return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+	}
+
+	candles := []Candle{}
+	for _, c := range m.candles[candleType+"/"+interval] {
+		if !c.StartsAt.Before(from) && c.StartsAt.Before(to) {
+			candles = append(candles, c)
+		}
+	}
+	return candles, nil
+}
+
+func (s *Server) privateRoute(w http.ResponseWriter, a *account, r *http.Request, path []string, body []byte) (interface{}, *httpError) {
+	route := r.Method + " " + path[0]
+	if len(path) > 1 {
+		route += "/" + path[1]
+	}
+	switch {
+	case route == "GET account":
+		info := Account{AccountID: s.accountID, ActionsNeeded: []string{}}
+		if a.subaccount != nil {
+			info.SubaccountID = a.subaccount.ID
+		}
+		return info, nil
+	case route == "GET account/volume":
+		return s.volume, nil
+	case route == "GET account/fees" && len(path) > 2 && path[2] == "trading":
+		fees := []TradingFee{}
+		for _, m := range s.sortedMarkets() {
+			if len(path) == 4 && m.Symbol != strings.ToUpper(path[3]) {
+				continue
+			}
+			fees = append(fees, TradingFee{MarketSymbol: m.Symbol, MakerRate: s.commissionRate, TakerRate: s.commissionRate})
+		}
+		if len(path) == 4 {
+			if len(fees) == 0 {
+				return nil, newHTTPError(http.StatusNotFound, "MARKET_DOES_NOT_EXIST")
+			}
+			return fees[0], nil
+		}
+		return fees, nil
+	case route == "GET account/permissions" && len(path) > 2 && path[2] == "markets":
+		permissions := []MarketPermission{}
+		for _, m := range s.sortedMarkets() {
+			if len(path) == 4 && m.Symbol != strings.ToUpper(path[3]) {
+				continue
+			}
+			permissions = append(permissions, MarketPermission{Symbol: m.Symbol, View: true, Buy: true, Sell: true})
+		}
+		if len(path) == 4 && len(permissions) == 0 {
+			return nil, newHTTPError(http.StatusNotFound, "MARKET_DOES_NOT_EXIST")
+		}
+		return permissions, nil
+	case route == "GET account/permissions" && len(path) > 2 && path[2] == "currencies":
+		funding := FundingPermissions{Blockchain: true}
+		permissions := []CurrencyPermission{}
+		for _, symbol := range sortedKeys(s.currencies) {
+			if len(path) == 4 && symbol != strings.ToUpper(path[3]) {
+				continue
+			}
+			permissions = append(permissions, CurrencyPermission{Symbol: symbol, View: true, Deposit: funding, Withdraw: funding})
+		}
+		if len(path) == 4 && len(permissions) == 0 {
+			return nil, newHTTPError(http.StatusNotFound, "CURRENCY_DOES_NOT_EXIST")
+		}
+		return permissions, nil
+
+	case route == "GET balances":
+		balances := []Balance{}
+		for _, currency := range sortedKeys(a.balances) {
+			balances = append(balances, s.balance(a, currency))
+		}
+		return balances, nil
+	case r.Method == "GET" && path[0] == "balances":
+		return s.balance(a, strings.ToUpper(path[1])), nil
+
+	case route == "GET addresses":
+		addresses := []*Address{}
+		for _, currency := range sortedKeys(a.addresses) {
+			addresses = append(addresses, a.addresses[currency])
+		}
+		return addresses, nil
+	case r.Method == "GET" && path[0] == "addresses":
+		address, ok := a.addresses[strings.ToUpper(path[1])]
+		if !ok {
+			return nil, newHTTPError(http.StatusNotFound, "CRYPTO_ADDRESS_NOT_FOUND")
+		}
+		return address, nil
+	case route == "POST addresses":
+		var req struct {
+			CurrencySymbol string `json:"currencySymbol"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+		}
+		if _, ok := s.currencies[req.CurrencySymbol]; !ok {
+			return nil, newHTTPError(http.StatusNotFound, "CURRENCY_DOES_NOT_EXIST")
+		}
+		if _, ok := a.addresses[req.CurrencySymbol]; ok {
+			return nil, newHTTPError(http.StatusConflict, "CRYPTO_ADDRESS_ALREADY_EXISTS")
+		}
+		address := &Address{
+			Status:         "PROVISIONED",
+			CurrencySymbol: req.CurrencySymbol,
+			CryptoAddress:  fmt.Sprintf("%s-%s", strings.ToLower(req.CurrencySymbol), uuid.New().String()[:8]),
+		}
+		a.addresses[req.CurrencySymbol] = address
+		return address, nil
+
+	case route == "GET deposits/open":
+		deposits := []*Deposit{}
+		for _, d := range a.deposits {
+			if d.Status == "PENDING" && s.matches(r, d.CurrencySymbol, d.Status) {
+				deposits = append(deposits, d)
+			}
+		}
+		return deposits, nil
+	case route == "GET deposits/closed":
+		deposits := []*Deposit{}
+		for i := len(a.deposits) - 1; i >= 0; i-- {
+			if d := a.deposits[i]; d.Status != "PENDING" && s.matches(r, d.CurrencySymbol, d.Status) {
+				deposits = append(deposits, d)
+			}
+		}
+		indexes, herr := paginate(r, len(deposits), func(i int) (string, time.Time) { return deposits[i].ID, deposits[i].UpdatedAt })
+		page := []*Deposit{}
+		for _, i := range indexes {
+			page = append(page, deposits[i])
+		}
+		return page, herr
+
+	case route == "POST withdrawals":
+		return s.withdraw(a, body)
+	case route == "GET withdrawals/open":
+		withdrawals := []*Withdrawal{}
+		for _, w := range a.withdrawals {
+			if openWithdrawal(w) && s.matches(r, w.CurrencySymbol, w.Status) {
+				withdrawals = append(withdrawals, w)
+			}
+		}
+		return withdrawals, nil
+	case route == "GET withdrawals/closed":
+		withdrawals := []*Withdrawal{}
+		for i := len(a.withdrawals) - 1; i >= 0; i-- {
+			if w := a.withdrawals[i]; !openWithdrawal(w) && s.matches(r, w.CurrencySymbol, w.Status) {
+				withdrawals = append(withdrawals, w)
+			}
+		}
+		indexes, herr := paginate(r, len(withdrawals), func(i int) (string, time.Time) { return withdrawals[i].ID, withdrawals[i].CreatedAt })
+		page := []*Withdrawal{}
+		for _, i := range indexes {
+			page = append(page, withdrawals[i])
+		}
+		return page, herr
+	case route == "GET withdrawals/ByTxId" && len(path) == 3:
+		withdrawals := []*Withdrawal{}
+		for _, w := range a.withdrawals {
+			if w.TxID == path[2] {
+				withdrawals = append(withdrawals, w)
+			}
+		}
+		return withdrawals, nil
+
+	case route == "POST orders":
+		var req NewOrder
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+		}
+		return s.placeOrder(a, req)
+	case route == "GET orders/open", route == "GET orders/closed":
+		orders := []*Order{}
+		for i := len(a.orderIDs) - 1; i >= 0; i-- {
+			o := a.orders[a.orderIDs[i]]
+			if (o.Status == statusOpen) == (path[1] == "open") && s.matches(r, o.MarketSymbol, "") {
+				orders = append(orders, o)
+			}
+		}
+		if path[1] == "open" {
+			w.Header().Set("Sequence", strconv.FormatInt(a.orderSequence, 10))
+			return orders, nil
+		}
+		indexes, herr := paginate(r, len(orders), func(i int) (string, time.Time) { return orders[i].ID, *orders[i].ClosedAt })
+		page := []*Order{}
+		for _, i := range indexes {
+			page = append(page, orders[i])
+		}
+		return page, herr
+	case r.Method == "GET" && path[0] == "orders" && len(path) == 3 && path[2] == "executions":
+		if _, ok := a.orders[path[1]]; !ok {
+			return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+		}
+		executions := []*Execution{}
+		for _, e := range a.executions {
+			if e.OrderID == path[1] {
+				executions = append(executions, e)
+			}
+		}
+		return executions, nil
+	case r.Method == "GET" && path[0] == "orders" && len(path) == 2:
+		o, ok := a.orders[path[1]]
+		if !ok {
+			return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+		}
+		return o, nil
+	case route == "DELETE orders/open":
+		type cancelResult struct {
+			ID         string `json:"id"`
+			StatusCode string `json:"statusCode"`
+			Result     *Order `json:"result,omitempty"`
+		}
+		results := []cancelResult{}
+		for _, id := range a.orderIDs {
+			o := a.orders[id]
+			if o.Status == statusOpen && s.matches(r, o.MarketSymbol, "") {
+				s.close(a, o)
+				results = append(results, cancelResult{ID: o.ID, StatusCode: "SUCCESS", Result: o})
+			}
+		}
+		return results, nil
+	case r.Method == "DELETE" && path[0] == "orders" && len(path) == 2:
+		return s.cancelOrder(a, path[1])
+
+	case route == "POST batch":
+		return s.batch(a, body)
+
+	case route == "GET executions":
+		executions := []*Execution{}
+		for i := len(a.executions) - 1; i >= 0; i-- {
+			if e := a.executions[i]; s.matches(r, e.MarketSymbol, "") {
+				executions = append(executions, e)
+			}
+		}
+		indexes, herr := paginate(r, len(executions), func(i int) (string, time.Time) { return executions[i].ID, executions[i].ExecutedAt })
+		page := []*Execution{}
+		for _, i := range indexes {
+			page = append(page, executions[i])
+		}
+		return page, herr
+	case route == "GET executions/last-id":
+		last := struct {
+			LastID string `json:"lastId"`
+		}{}
+		if n := len(a.executions); n > 0 {
+			last.LastID = a.executions[n-1].ID
+		}
+		return last, nil
+	case r.Method == "GET" && path[0] == "executions" && len(path) == 2:
+		for _, e := range a.executions {
+			if e.ID == path[1] {
+				return e, nil
+			}
+		}
+		return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+
+	case route == "GET subaccounts":
+		subaccounts := []*Subaccount{}
+		for i := len(s.subaccountIDs) - 1; i >= 0; i-- {
+			subaccounts = append(subaccounts, s.subaccounts[s.subaccountIDs[i]].subaccount)
+		}
+		indexes, herr := paginate(r, len(subaccounts), func(i int) (string, time.Time) { return subaccounts[i].ID, subaccounts[i].CreatedAt })
+		page := []*Subaccount{}
+		for _, i := range indexes {
+			page = append(page, subaccounts[i])
+		}
+		return page, herr
+	case route == "POST subaccounts":
+		if a.subaccount != nil {
+			return nil, newHTTPError(http.StatusForbidden, "NOT_ALLOWED")
+		}
+		sub := newAccount()
+		sub.subaccount = &Subaccount{ID: uuid.New().String(), CreatedAt: s.now()}
+		s.subaccounts[sub.subaccount.ID] = sub
+		s.subaccountIDs = append(s.subaccountIDs, sub.subaccount.ID)
+		return sub.subaccount, nil
+	case r.Method == "GET" && path[0] == "subaccounts" && len(path) == 2:
+		sub, ok := s.subaccounts[path[1]]
+		if !ok {
+			return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+		}
+		return sub.subaccount, nil
+
+	case route == "POST transfers":
+		return s.transfer(a, body)
+	case route == "GET transfers/sent", route == "GET transfers/received":
+		transfers := a.transfersSent
+		if path[1] == "received" {
+			transfers = a.transfersReceived
+		}
+		q := r.URL.Query()
+		matching := []*Transfer{}
+		for i := len(transfers) - 1; i >= 0; i-- {
+			t := transfers[i]
+			if v := q.Get("currencySymbol"); v != "" && v != t.CurrencySymbol ||
+				q.Get("toSubaccountId") != "" && q.Get("toSubaccountId") != t.ToSubaccountID ||
+				q.Get("fromSubaccountId") != "" && q.Get("fromSubaccountId") != t.FromSubaccountID ||
+				q.Get("toMasterAccount") == "true" && !t.ToMasterAccount ||
+				q.Get("fromMasterAccount") == "true" && !t.FromMasterAccount {
+				continue
+			}
+			matching = append(matching, t)
+		}
+		indexes, herr := paginate(r, len(matching), func(i int) (string, time.Time) { return matching[i].ID, matching[i].ExecutedAt })
+		page := []*Transfer{}
+		for _, i := range indexes {
+			page = append(page, matching[i])
+		}
+		return page, herr
+	case r.Method == "GET" && path[0] == "transfers" && len(path) == 2:
+		for _, t := range append(a.transfersSent, a.transfersReceived...) {
+			if t.ID == path[1] {
+				return t, nil
+			}
+		}
+		return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+
+	case route == "POST conditional-orders":
+		var req ConditionalOrder
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+		}
+		return s.placeConditionalOrder(a, req)
+	case route == "GET conditional-orders/open", route == "GET conditional-orders/closed":
+		orders := []*ConditionalOrder{}
+		for i := len(a.conditionalIDs) - 1; i >= 0; i-- {
+			c := a.conditionalOrders[a.conditionalIDs[i]]
+			if (c.Status == conditionalOpen) == (path[1] == "open") && s.matches(r, c.MarketSymbol, "") {
+				orders = append(orders, &c.ConditionalOrder)
+			}
+		}
+		return orders, nil
+	case r.Method == "GET" && path[0] == "conditional-orders" && len(path) == 2:
+		c, ok := a.conditionalOrders[path[1]]
+		if !ok {
+			return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+		}
+		return &c.ConditionalOrder, nil
+	case r.Method == "DELETE" && path[0] == "conditional-orders" && len(path) == 2:
+		c, ok := a.conditionalOrders[path[1]]
+		if !ok {
+			return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+		}
+		if c.Status != conditionalOpen {
+			return nil, newHTTPError(http.StatusConflict, "CONDITIONAL_ORDER_NOT_OPEN")
+		}
+		s.cancelConditional(a, c)
+		return &c.ConditionalOrder, nil
+	}
+	return nil, newHTTPError(http.StatusNotFound, "NOT_FOUND")
+}
+
+// batch runs the operations of a batch request, each answering its own status and payload.
+func (s *Server) batch(a *account, body []byte) (interface{}, *httpError) {
+	var operations []struct {
+		Resource  string          `json:"resource"`
+		Operation string          `json:"operation"`
+		Payload   json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+	}
+	type batchResult struct {
+		Status  int         `json:"status"`
+		Payload interface{} `json:"payload"`
+	}
+	results := []batchResult{}
+	for _, op := range operations {
+		var order *Order
+		herr := newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+		status := http.StatusOK
+		switch op.Resource + " " + op.Operation {
+		case "order post":
+			var req NewOrder
+			if json.Unmarshal(op.Payload, &req) == nil {
+				order, herr = s.placeOrder(a, req)
+				status = http.StatusCreated
+			}
+		case "order delete":
+			var req struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal(op.Payload, &req) == nil {
+				order, herr = s.cancelOrder(a, req.ID)
+			}
+		}
+		if herr != nil {
+			results = append(results, batchResult{Status: herr.status, Payload: herr.body})
+			continue
+		}
+		results = append(results, batchResult{Status: status, Payload: order})
+	}
+	return results, nil
+}
+
+// transfer moves funds between the master account and a subaccount.
+func (s *Server) transfer(a *account, body []byte) (interface{}, *httpError) {
+	var req struct {
+		ToSubaccountID  string          `json:"toSubaccountId"`
+		ToMasterAccount bool            `json:"toMasterAccount"`
+		RequestID       string          `json:"requestId"`
+		CurrencySymbol  string          `json:"currencySymbol"`
+		Amount          decimal.Decimal `json:"amount"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+	}
+	if req.RequestID == "" || !req.Amount.IsPositive() {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_TRANSFER")
+	}
+	var to *account
+	switch {
+	case req.ToMasterAccount && req.ToSubaccountID == "" && a.subaccount != nil:
+		to = s.account
+	case !req.ToMasterAccount && req.ToSubaccountID != "" && a.subaccount == nil:
+		var ok bool
+		if to, ok = s.subaccounts[req.ToSubaccountID]; !ok {
+			return nil, newHTTPError(http.StatusNotFound, "SUBACCOUNT_NOT_FOUND")
+		}
+	default:
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_TRANSFER")
+	}
+	if _, ok := s.currencies[req.CurrencySymbol]; !ok {
+		return nil, newHTTPError(http.StatusNotFound, "CURRENCY_DOES_NOT_EXIST")
+	}
+	for _, t := range a.transfersSent {
+		if t.RequestID == req.RequestID {
+			return nil, newHTTPError(http.StatusConflict, "DUPLICATE_REQUEST_ID")
+		}
+	}
+	from := a.ledger(req.CurrencySymbol)
+	if from.available().LessThan(req.Amount) {
+		return nil, newHTTPError(http.StatusBadRequest, "INSUFFICIENT_FUNDS")
+	}
+
+	now := s.now()
+	into := to.ledger(req.CurrencySymbol)
+	from.total = from.total.Sub(req.Amount)
+	into.total = into.total.Add(req.Amount)
+	from.updatedAt, into.updatedAt = now, now
+
+	sent := &Transfer{
+		ID:              uuid.New().String(),
+		RequestID:       req.RequestID,
+		CurrencySymbol:  req.CurrencySymbol,
+		Amount:          req.Amount,
+		ExecutedAt:      now,
+		ToSubaccountID:  req.ToSubaccountID,
+		ToMasterAccount: req.ToMasterAccount,
+	}
+	received := *sent
+	received.ToSubaccountID, received.ToMasterAccount = "", false
+	if a.subaccount != nil {
+		received.FromSubaccountID = a.subaccount.ID
+	} else {
+		received.FromMasterAccount = true
+	}
+	a.transfersSent = append(a.transfersSent, sent)
+	to.transfersReceived = append(to.transfersReceived, &received)
+	return sent, nil
+}
+
+// withdraw debits the account and records a REQUESTED withdrawal.
+func (s *Server) withdraw(a *account, body []byte) (interface{}, *httpError) {
+	var req struct {
+		CurrencySymbol   string          `json:"currencySymbol"`
+		Quantity         decimal.Decimal `json:"quantity"`
+		CryptoAddress    string          `json:"cryptoAddress"`
+		CryptoAddressTag string          `json:"cryptoAddressTag"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, newHTTPError(http.StatusBadRequest, "BAD_REQUEST")
+	}
+	c, ok := s.currencies[req.CurrencySymbol]
+	if !ok {
+		return nil, newHTTPError(http.StatusNotFound, "CURRENCY_DOES_NOT_EXIST")
+	}
+	if !req.Quantity.IsPositive() || req.CryptoAddress == "" {
+		return nil, newHTTPError(http.StatusBadRequest, "INVALID_WITHDRAWAL")
+	}
+	l := a.ledger(req.CurrencySymbol)
+	if l.available().LessThan(req.Quantity) {
+		return nil, newHTTPError(http.StatusBadRequest, "INSUFFICIENT_FUNDS")
+	}
+	now := s.now()
+	l.total = l.total.Sub(req.Quantity)
+	l.updatedAt = now
+	withdrawal := &Withdrawal{
+		ID:               uuid.New().String(),
+		CurrencySymbol:   req.CurrencySymbol,
+		Quantity:         req.Quantity,
+		CryptoAddress:    req.CryptoAddress,
+		CryptoAddressTag: req.CryptoAddressTag,
+		TxCost:           c.TxFee,
+		Status:           "REQUESTED",
+		CreatedAt:        now,
+	}
+	a.withdrawals = append(a.withdrawals, withdrawal)
+	return withdrawal, nil
+}
+
+func openWithdrawal(w *Withdrawal) bool {
+	return w.Status == "REQUESTED" || w.Status == "AUTHORIZED" || w.Status == "PENDING"
+}
+
+// paginate applies the date and pagination query parameters of history endpoints to n items
+// listed from the most recent, whose id and date item returns. It returns the indexes of the page.
+func paginate(r *http.Request, n int, item func(i int) (id string, at time.Time)) ([]int, *httpError) {
+	q := r.URL.Query()
+	size := 100
+	if v := q.Get("pageSize"); v != "" {
+		var err error
+		if size, err = strconv.Atoi(v); err != nil || size < 1 || size > 200 {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_PAGE_SIZE")
+		}
+	}
+	var bounds [2]time.Time
+	for i, name := range []string{"startDate", "endDate"} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, newHTTPError(http.StatusBadRequest, "INVALID_DATE")
+			}
+			bounds[i] = t
+		}
+	}
+
+	// Position of the page token in the list, the page follows or precedes it
+	from, to := 0, n
+	next, previous := q.Get("nextPageToken"), q.Get("previousPageToken")
+	if next != "" || previous != "" {
+		token := -1
+		for i := 0; i < n; i++ {
+			if id, _ := item(i); id == next || id == previous {
+				token = i
+				break
+			}
+		}
+		if token < 0 {
+			return nil, newHTTPError(http.StatusBadRequest, "INVALID_PAGE_TOKEN")
+		}
+		if next != "" {
+			from = token + 1
+		} else {
+			to = token
+		}
+	}
+
+	var indexes []int
+	for i := from; i < to; i++ {
+		_, at := item(i)
+		if !bounds[0].IsZero() && at.Before(bounds[0]) || !bounds[1].IsZero() && !at.Before(bounds[1]) {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	if len(indexes) > size {
+		if previous != "" {
+			indexes = indexes[len(indexes)-size:]
+		} else {
+			indexes = indexes[:size]
+		}
+	}
+	return indexes, nil
+}
+
+// matches applies the symbol and status query filters of history endpoints.
+func (s *Server) matches(r *http.Request, symbol, status string) bool {
+	q := r.URL.Query()
+	if v := q.Get("marketSymbol"); v != "" && v != symbol {
+		return false
+	}
+	if v := q.Get("currencySymbol"); v != "" && v != symbol {
+		return false
+	}
+	if v := q.Get("status"); v != "" && v != status {
+		return false
+	}
+	return true
+}
+
+func (s *Server) balance(a *account, currency string) Balance {
+	l := a.ledger(currency)
+	return Balance{
+		CurrencySymbol: currency,
+		Total:          l.total,
+		Available:      l.available(),
+		UpdatedAt:      l.updatedAt,
+	}
+}
+
+func (s *Server) ticker(m *market) Ticker {
+	t := Ticker{Symbol: m.Symbol}
+	if n := len(m.trades); n > 0 {
+		t.LastTradeRate = m.trades[n-1].Rate
+	}
+	if len(m.bids) > 0 {
+		t.BidRate = m.bids[0].rate
+	}
+	if len(m.asks) > 0 {
+		t.AskRate = m.asks[0].rate
+	}
+	return t
+}
+
+func (s *Server) summary(m *market) MarketSummary {
+	now := s.now()
+	summary := MarketSummary{Symbol: m.Symbol, UpdatedAt: now}
+	var first decimal.Decimal
+	for _, t := range m.trades {
+		if now.Sub(t.ExecutedAt) > 24*time.Hour {
+			continue
+		}
+		if first.IsZero() {
+			first, summary.High, summary.Low = t.Rate, t.Rate, t.Rate
+		}
+		summary.High = decimal.Max(summary.High, t.Rate)
+		summary.Low = decimal.Min(summary.Low, t.Rate)
+		summary.Volume = summary.Volume.Add(t.Quantity)
+		summary.QuoteVolume = summary.QuoteVolume.Add(t.Quantity.Mul(t.Rate))
+		last := t.Rate
+		summary.PercentChange = last.Sub(first).Div(first).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+	return summary
+}
+
+func (s *Server) sortedMarkets() []*market {
+	markets := make([]*market, 0, len(s.markets))
+	for _, m := range s.markets {
+		markets = append(markets, m)
+	}
+	sort.Slice(markets, func(i, j int) bool { return markets[i].Symbol < markets[j].Symbol })
+	return markets
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*ledger:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Address:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Currency:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		status = http.StatusInternalServerError
+		buf.Reset()
+		buf.WriteString(`{"code":"INTERNAL_ERROR"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
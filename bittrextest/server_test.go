@@ -0,0 +1,154 @@
+package bittrextest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	bittrex "github.com/mountalpha/basecamp-bittrex-connector"
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func newTestServer(t *testing.T) (*bittrextest.Server, *bittrex.Bittrex) {
+	srv := bittrextest.NewServer("key", "secret")
+	t.Cleanup(srv.Close)
+	srv.AddMarket(bittrextest.Market{
+		Symbol:              "LTC-BTC",
+		BaseCurrencySymbol:  "LTC",
+		QuoteCurrencySymbol: "BTC",
+		MinTradeSize:        d("0.01"),
+		Precision:           8,
+	})
+	srv.SetCommissionRate(decimal.Zero)
+	bt := bittrex.New("key", "secret",
+		bittrex.WithBaseURL(srv.URL()),
+		bittrex.WithRetryPolicy(bittrex.NoRetry),
+		bittrex.WithRateLimits(nil, bittrex.RATE_LIMIT_BLOCK),
+	)
+	return srv, bt
+}
+
+func TestServerMarketData(t *testing.T) {
+	srv, bt := newTestServer(t)
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0041"), d("5"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0040"), d("2"))
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0039"), d("3"))
+
+	markets, err := bt.GetMarkets()
+	assert.Nil(t, err)
+	if assert.Len(t, markets, 1) {
+		assert.Equal(t, "LTC-BTC", markets[0].Symbol)
+		assert.True(t, d("0.01").Equal(markets[0].MinTradeSize))
+	}
+
+	currencies, err := bt.GetCurrencies()
+	assert.Nil(t, err)
+	assert.Len(t, currencies, 2)
+
+	ticker, err := bt.GetTicker("ltc-btc")
+	assert.Nil(t, err)
+	if assert.Len(t, ticker, 1) {
+		assert.True(t, d("0.0039").Equal(ticker[0].BidRate))
+		assert.True(t, d("0.004").Equal(ticker[0].AskRate))
+	}
+
+	book, err := bt.GetOrderBook("LTC-BTC", 25, "both")
+	assert.Nil(t, err)
+	if assert.Len(t, book.Ask, 2) && assert.Len(t, book.Bid, 1) {
+		assert.True(t, d("0.004").Equal(book.Ask[0].Rate))
+		assert.True(t, d("2").Equal(book.Ask[0].Quantity))
+	}
+
+	_, err = bt.GetMarketSummary("DOGE-BTC")
+	assert.True(t, bittrex.IsNotFound(err))
+}
+
+func TestServerOrders(t *testing.T) {
+	srv, bt := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("2"))
+
+	// Crosses the book for 2 LTC, rests the remaining 1 LTC
+	order, err := bt.CreateOrder(bittrex.CreateOrderParams{
+		MarketSymbol:  "LTC-BTC",
+		Direction:     bittrex.BUY,
+		Type:          bittrex.LIMIT,
+		Quantity:      d("3"),
+		Limit:         d("0.005"),
+		TimeInForce:   bittrex.GOOD_TIL_CANCELLED,
+		ClientOrderID: "my-order",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "OPEN", order.Status)
+	assert.Equal(t, "my-order", order.ClientOrderID)
+	assert.True(t, d("2").Equal(order.FillQuantity))
+	assert.True(t, d("0.008").Equal(order.Proceeds))
+
+	balance := srv.Balance("BTC")
+	assert.True(t, d("0.992").Equal(balance.Total))
+	assert.True(t, d("0.985").Equal(balance.Available))
+
+	open, err := bt.GetOpenOrders("LTC-BTC")
+	assert.Nil(t, err)
+	assert.Len(t, open, 1)
+
+	// Someone sells into our bid
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.005"), d("0.5"))
+	o, _ := srv.Order(order.ID)
+	assert.True(t, d("2.5").Equal(o.FillQuantity))
+
+	cancelled, err := bt.CancelOrder(order.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "CLOSED", cancelled.Status)
+	balance = srv.Balance("BTC")
+	assert.True(t, d("0.9895").Equal(balance.Total))
+	assert.True(t, balance.Total.Equal(balance.Available))
+	assert.True(t, d("2.5").Equal(srv.Balance("LTC").Total))
+
+	closed, err := bt.GetClosedOrders("all")
+	assert.Nil(t, err)
+	assert.Len(t, closed, 1)
+	assert.Len(t, srv.Executions(), 2)
+
+	_, err = bt.CancelOrder(order.ID)
+	assert.Error(t, err)
+
+	_, err = bt.CreateOrder(bittrex.CreateOrderParams{
+		MarketSymbol: "LTC-BTC",
+		Direction:    bittrex.BUY,
+		Type:         bittrex.LIMIT,
+		Quantity:     d("1000"),
+		Limit:        d("0.005"),
+		TimeInForce:  bittrex.GOOD_TIL_CANCELLED,
+	})
+	assert.True(t, bittrex.IsInsufficientFunds(err))
+}
+
+func TestServerAuthentication(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	bt := bittrex.New("key", "wrong secret", bittrex.WithBaseURL(srv.URL()), bittrex.WithRetryPolicy(bittrex.NoRetry))
+	_, err := bt.GetBalances()
+	assert.True(t, bittrex.IsUnauthorized(err))
+
+	bt = bittrex.New("other key", "secret", bittrex.WithBaseURL(srv.URL()), bittrex.WithRetryPolicy(bittrex.NoRetry))
+	_, err = bt.GetOpenOrders("all")
+	assert.True(t, bittrex.IsUnauthorized(err))
+}
+
+func TestServerFailNext(t *testing.T) {
+	srv, bt := newTestServer(t)
+	bt.SetRetryPolicy(bittrex.RetryPolicy{MaxAttempts: 3})
+
+	srv.FailNext(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE")
+	srv.FailNext(http.StatusTooManyRequests, "TOO_MANY_REQUESTS")
+	_, err := bt.GetMarkets()
+	assert.Nil(t, err)
+	assert.Len(t, srv.Requests(), 3)
+}
@@ -0,0 +1,276 @@
+package bittrextest
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Market mirrors the v3 market object.
+type Market struct {
+	Symbol              string          `json:"symbol"`
+	BaseCurrencySymbol  string          `json:"baseCurrencySymbol"`
+	QuoteCurrencySymbol string          `json:"quoteCurrencySymbol"`
+	MinTradeSize        decimal.Decimal `json:"minTradeSize"`
+	Precision           int32           `json:"precision"`
+	Status              string          `json:"status"`
+	CreatedAt           time.Time       `json:"createdAt"`
+	Notice              string          `json:"notice,omitempty"`
+	ProhibitedIn        []string        `json:"prohibitedIn"`
+}
+
+// Currency mirrors the v3 currency object.
+type Currency struct {
+	Symbol           string          `json:"symbol"`
+	Name             string          `json:"name"`
+	CoinType         string          `json:"coinType"`
+	Status           string          `json:"status"`
+	MinConfirmations int             `json:"minConfirmations"`
+	Notice           string          `json:"notice"`
+	TxFee            decimal.Decimal `json:"txFee"`
+	ProhibitedIn     []string        `json:"prohibitedIn"`
+}
+
+// Ticker mirrors the v3 ticker object.
+type Ticker struct {
+	Symbol        string          `json:"symbol"`
+	LastTradeRate decimal.Decimal `json:"lastTradeRate"`
+	BidRate       decimal.Decimal `json:"bidRate"`
+	AskRate       decimal.Decimal `json:"askRate"`
+}
+
+// MarketSummary mirrors the v3 market summary object.
+type MarketSummary struct {
+	Symbol        string          `json:"symbol"`
+	High          decimal.Decimal `json:"high"`
+	Low           decimal.Decimal `json:"low"`
+	Volume        decimal.Decimal `json:"volume"`
+	QuoteVolume   decimal.Decimal `json:"quoteVolume"`
+	PercentChange decimal.Decimal `json:"percentChange"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+// OrderBookEntry is a price level of the v3 order book.
+type OrderBookEntry struct {
+	Quantity decimal.Decimal `json:"quantity"`
+	Rate     decimal.Decimal `json:"rate"`
+}
+
+// OrderBook mirrors the v3 order book object.
+type OrderBook struct {
+	Bid []OrderBookEntry `json:"bid"`
+	Ask []OrderBookEntry `json:"ask"`
+}
+
+// Trade mirrors the v3 market trade object.
+type Trade struct {
+	ID         string          `json:"id"`
+	ExecutedAt time.Time       `json:"executedAt"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Rate       decimal.Decimal `json:"rate"`
+	TakerSide  string          `json:"takerSide"`
+}
+
+// Candle mirrors the v3 candle object.
+type Candle struct {
+	StartsAt    time.Time       `json:"startsAt"`
+	Open        decimal.Decimal `json:"open"`
+	High        decimal.Decimal `json:"high"`
+	Low         decimal.Decimal `json:"low"`
+	Close       decimal.Decimal `json:"close"`
+	Volume      decimal.Decimal `json:"volume"`
+	QuoteVolume decimal.Decimal `json:"quoteVolume"`
+}
+
+// Balance mirrors the v3 balance object.
+type Balance struct {
+	CurrencySymbol string          `json:"currencySymbol"`
+	Total          decimal.Decimal `json:"total"`
+	Available      decimal.Decimal `json:"available"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// Address mirrors the v3 deposit address object.
+type Address struct {
+	Status           string `json:"status"`
+	CurrencySymbol   string `json:"currencySymbol"`
+	CryptoAddress    string `json:"cryptoAddress"`
+	CryptoAddressTag string `json:"cryptoAddressTag,omitempty"`
+}
+
+// Deposit mirrors the v3 deposit object.
+type Deposit struct {
+	ID               string          `json:"id"`
+	CurrencySymbol   string          `json:"currencySymbol"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	CryptoAddress    string          `json:"cryptoAddress"`
+	CryptoAddressTag string          `json:"cryptoAddressTag,omitempty"`
+	TxID             string          `json:"txId"`
+	Confirmations    int32           `json:"confirmations"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
+	CompletedAt      *time.Time      `json:"completedAt,omitempty"`
+	Status           string          `json:"status"`
+	Source           string          `json:"source"`
+}
+
+// Withdrawal mirrors the v3 withdrawal object.
+type Withdrawal struct {
+	ID               string          `json:"id"`
+	CurrencySymbol   string          `json:"currencySymbol"`
+	Quantity         decimal.Decimal `json:"quantity"`
+	CryptoAddress    string          `json:"cryptoAddress"`
+	CryptoAddressTag string          `json:"cryptoAddressTag,omitempty"`
+	TxCost           decimal.Decimal `json:"txCost"`
+	TxID             string          `json:"txId,omitempty"`
+	Status           string          `json:"status"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	CompletedAt      *time.Time      `json:"completedAt,omitempty"`
+}
+
+// Order mirrors the v3 order object.
+type Order struct {
+	ID            string           `json:"id"`
+	MarketSymbol  string           `json:"marketSymbol"`
+	Direction     string           `json:"direction"`
+	Type          string           `json:"type"`
+	Quantity      *decimal.Decimal `json:"quantity,omitempty"`
+	Limit         *decimal.Decimal `json:"limit,omitempty"`
+	Ceiling       *decimal.Decimal `json:"ceiling,omitempty"`
+	TimeInForce   string           `json:"timeInForce"`
+	ClientOrderID string           `json:"clientOrderId,omitempty"`
+	FillQuantity  decimal.Decimal  `json:"fillQuantity"`
+	Commission    decimal.Decimal  `json:"commission"`
+	Proceeds      decimal.Decimal  `json:"proceeds"`
+	Status        string           `json:"status"`
+	CreatedAt     time.Time        `json:"createdAt"`
+	UpdatedAt     time.Time        `json:"updatedAt"`
+	ClosedAt      *time.Time       `json:"closedAt,omitempty"`
+}
+
+// Execution mirrors the v3 execution (fill) object.
+type Execution struct {
+	ID           string          `json:"id"`
+	MarketSymbol string          `json:"marketSymbol"`
+	ExecutedAt   time.Time       `json:"executedAt"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	Rate         decimal.Decimal `json:"rate"`
+	OrderID      string          `json:"orderId"`
+	Commission   decimal.Decimal `json:"commission"`
+	IsTaker      bool            `json:"isTaker"`
+}
+
+// Subaccount mirrors the v3 subaccount object.
+type Subaccount struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Transfer mirrors the v3 sent and received transfer objects, with the To fields set on
+// sent transfers and the From fields on received ones.
+type Transfer struct {
+	ID                string          `json:"id"`
+	RequestID         string          `json:"requestId"`
+	CurrencySymbol    string          `json:"currencySymbol"`
+	Amount            decimal.Decimal `json:"amount"`
+	ExecutedAt        time.Time       `json:"executedAt"`
+	ToSubaccountID    string          `json:"toSubaccountId,omitempty"`
+	ToMasterAccount   bool            `json:"toMasterAccount,omitempty"`
+	FromSubaccountID  string          `json:"fromSubaccountId,omitempty"`
+	FromMasterAccount bool            `json:"fromMasterAccount,omitempty"`
+}
+
+// Account mirrors the v3 account object.
+type Account struct {
+	SubaccountID  string   `json:"subaccountId,omitempty"`
+	AccountID     string   `json:"accountId"`
+	ActionsNeeded []string `json:"actionsNeeded"`
+}
+
+// AccountVolume mirrors the v3 account volume object.
+type AccountVolume struct {
+	Updated      time.Time       `json:"updated"`
+	Volume30days decimal.Decimal `json:"volume30days"`
+}
+
+// TradingFee mirrors the v3 commission rates of a market.
+type TradingFee struct {
+	MarketSymbol string          `json:"marketSymbol"`
+	MakerRate    decimal.Decimal `json:"makerRate"`
+	TakerRate    decimal.Decimal `json:"takerRate"`
+}
+
+// MarketPermission mirrors the v3 market policy object.
+type MarketPermission struct {
+	Symbol string `json:"symbol"`
+	View   bool   `json:"view"`
+	Buy    bool   `json:"buy"`
+	Sell   bool   `json:"sell"`
+}
+
+// FundingPermissions mirrors the funding methods of the v3 currency policy object.
+type FundingPermissions struct {
+	Blockchain   bool `json:"blockchain"`
+	CreditCard   bool `json:"creditCard"`
+	WireTransfer bool `json:"wireTransfer"`
+	ACH          bool `json:"ach"`
+}
+
+// CurrencyPermission mirrors the v3 currency policy object.
+type CurrencyPermission struct {
+	Symbol   string             `json:"symbol"`
+	View     bool               `json:"view"`
+	Deposit  FundingPermissions `json:"deposit"`
+	Withdraw FundingPermissions `json:"withdraw"`
+}
+
+// NewOrder mirrors the v3 new order object, the body of POST /orders.
+type NewOrder struct {
+	MarketSymbol  string           `json:"marketSymbol"`
+	Direction     string           `json:"direction"`
+	Type          string           `json:"type"`
+	Quantity      *decimal.Decimal `json:"quantity,omitempty"`
+	Ceiling       *decimal.Decimal `json:"ceiling,omitempty"`
+	Limit         *decimal.Decimal `json:"limit,omitempty"`
+	TimeInForce   string           `json:"timeInForce"`
+	ClientOrderID string           `json:"clientOrderId,omitempty"`
+}
+
+// LinkedOrder references the order or conditional order cancelled by a conditional order.
+type LinkedOrder struct {
+	Type string `json:"type"` // ORDER or CONDITIONAL_ORDER
+	ID   string `json:"id"`
+}
+
+// ConditionalOrder mirrors the v3 conditional order object.
+type ConditionalOrder struct {
+	ID                       string           `json:"id"`
+	MarketSymbol             string           `json:"marketSymbol"`
+	Operand                  string           `json:"operand"`
+	TriggerPrice             *decimal.Decimal `json:"triggerPrice,omitempty"`
+	TrailingStopPercent      *decimal.Decimal `json:"trailingStopPercent,omitempty"`
+	CreatedOrderID           string           `json:"createdOrderId,omitempty"`
+	OrderToCreate            *NewOrder        `json:"orderToCreate,omitempty"`
+	OrderToCancel            *LinkedOrder     `json:"orderToCancel,omitempty"`
+	ClientConditionalOrderID string           `json:"clientConditionalOrderId,omitempty"`
+	Status                   string           `json:"status"`
+	OrderCreationErrorCode   string           `json:"orderCreationErrorCode,omitempty"`
+	CreatedAt                time.Time        `json:"createdAt"`
+	UpdatedAt                time.Time        `json:"updatedAt"`
+	ClosedAt                 *time.Time       `json:"closedAt,omitempty"`
+}
+
+// apiError is the v3 error body.
+type apiError struct {
+	Code   string      `json:"code"`
+	Detail string      `json:"detail,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+func (e *apiError) Error() string {
+	return e.Code
+}
+
+// DecimalPtr returns a pointer to d, for the optional fields of Order.
+func DecimalPtr(d decimal.Decimal) *decimal.Decimal {
+	return &d
+}
@@ -1,6 +1,12 @@
 package bittrex
 
-import "github.com/shopspring/decimal"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type Candle struct {
 	TimeStamp  CandleTime      `json:"T"`
@@ -15,3 +21,87 @@ type Candle struct {
 type NewCandles struct {
 	Ticks []Candle `json:"ticks"`
 }
+
+// CandleV3 is a v3 candle, StartsAt being the start of its interval.
+type CandleV3 struct {
+	StartsAt    time.Time       `json:"startsAt"`
+	Open        decimal.Decimal `json:"open"`
+	High        decimal.Decimal `json:"high"`
+	Low         decimal.Decimal `json:"low"`
+	Close       decimal.Decimal `json:"close"`
+	Volume      decimal.Decimal `json:"volume"`
+	QuoteVolume decimal.Decimal `json:"quoteVolume"`
+}
+
+// Duration returns the length of the interval, 0 if it is unknown.
+func (i CandleInterval) Duration() time.Duration {
+	switch i {
+	case MINUTE_1:
+		return time.Minute
+	case MINUTE_5:
+		return 5 * time.Minute
+	case HOUR_1:
+		return time.Hour
+	case DAY_1:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// historicalPeriod returns the period served by one call to the historical candles endpoint
+// containing t: a day for minute candles, a month for hourly ones and a year for daily ones.
+func (i CandleInterval) historicalPeriod(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	switch i {
+	case HOUR_1:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	case DAY_1:
+		start = time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, 0)
+	}
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// historicalPath returns the date part of the historical candles endpoint for the period starting at start.
+func (i CandleInterval) historicalPath(start time.Time) string {
+	switch i {
+	case HOUR_1:
+		return fmt.Sprintf("%d/%d", start.Year(), start.Month())
+	case DAY_1:
+		return fmt.Sprintf("%d", start.Year())
+	}
+	return fmt.Sprintf("%d/%d/%d", start.Year(), start.Month(), start.Day())
+}
+
+// continuousCandles sorts candles, drops duplicates and the ones outside [start, end), and fills
+// the intervals without candle between the first and the last one with flat candles at the
+// previous close and no volume.
+func continuousCandles(candles []CandleV3, interval time.Duration, start, end time.Time) []CandleV3 {
+	sort.SliceStable(candles, func(i, j int) bool { return candles[i].StartsAt.Before(candles[j].StartsAt) })
+
+	var series []CandleV3
+	for _, c := range candles {
+		if c.StartsAt.Before(start) || !c.StartsAt.Before(end) {
+			continue
+		}
+		if n := len(series); n > 0 {
+			last := series[n-1]
+			if !c.StartsAt.After(last.StartsAt) {
+				continue
+			}
+			for t := last.StartsAt.Add(interval); t.Before(c.StartsAt); t = t.Add(interval) {
+				series = append(series, CandleV3{
+					StartsAt: t,
+					Open:     last.Close,
+					High:     last.Close,
+					Low:      last.Close,
+					Close:    last.Close,
+				})
+			}
+		}
+		series = append(series, c)
+	}
+	return series
+}
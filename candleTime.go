@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// CANDLE_INTERVALS are the v2.0 intervals of GetTicks.
+//
+// Deprecated: v3 candles use CandleInterval.
 var CANDLE_INTERVALS = map[string]bool{
 	"oneMin":    true,
 	"fiveMin":   true,
@@ -0,0 +1,103 @@
+package bittrex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func TestCandles(t *testing.T) {
+	srv, b := newTestServer(t)
+
+	// 29 hours of 5 minutes candles ending an hour ago, three of them missing
+	start := time.Now().UTC().Truncate(5 * time.Minute).Add(-30 * time.Hour)
+	var candles []bittrextest.Candle
+	for i := 0; i < 348; i++ {
+		if i >= 100 && i < 103 {
+			continue
+		}
+		rate := decimal.NewFromInt(int64(i))
+		candles = append(candles, bittrextest.Candle{
+			StartsAt: start.Add(time.Duration(i) * 5 * time.Minute),
+			Open:     rate, High: rate, Low: rate, Close: rate,
+			Volume: d("1"),
+		})
+	}
+	srv.AddCandles("LTC-BTC", "TRADE", "MINUTE_5", candles...)
+
+	recent, err := b.GetCandles("LTC-BTC", CANDLE_TRADE, MINUTE_5)
+	assert.Nil(t, err)
+	if assert.NotEmpty(t, recent) {
+		assert.True(t, recent[0].StartsAt.After(time.Now().Add(-24*time.Hour)))
+		assert.True(t, d("347").Equal(recent[len(recent)-1].Close))
+	}
+
+	series, err := b.BackfillCandles("LTC-BTC", CANDLE_TRADE, MINUTE_5, start, start.Add(348*5*time.Minute))
+	assert.Nil(t, err)
+	if assert.Len(t, series, 348) {
+		for i, c := range series {
+			assert.True(t, c.StartsAt.Equal(start.Add(time.Duration(i)*5*time.Minute)))
+		}
+		assert.True(t, d("99").Equal(series[101].Close))
+		assert.True(t, series[101].Volume.IsZero())
+		assert.True(t, d("347").Equal(series[347].Close))
+	}
+
+	_, err = b.GetCandles("LTC-BTC", CANDLE_TRADE, "MINUTE_3")
+	assert.Error(t, err)
+	midpoint, err := b.GetHistoricalCandles("LTC-BTC", CANDLE_MIDPOINT, DAY_1, start)
+	assert.Nil(t, err)
+	assert.Empty(t, midpoint)
+}
+
+func TestHistoricalPeriod(t *testing.T) {
+	// Periods are in UTC: 1am on March 1st at UTC+2 is still February
+	at := time.Date(2021, 3, 1, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	for _, tc := range []struct {
+		interval   CandleInterval
+		start, end time.Time
+		path       string
+	}{
+		{MINUTE_1, time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), "2021/2/28"},
+		{MINUTE_5, time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), "2021/2/28"},
+		{HOUR_1, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), "2021/2"},
+		{DAY_1, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), "2021"},
+	} {
+		start, end := tc.interval.historicalPeriod(at)
+		assert.True(t, tc.start.Equal(start), "%s: %s", tc.interval, start)
+		assert.True(t, tc.end.Equal(end), "%s: %s", tc.interval, end)
+		assert.Equal(t, tc.path, tc.interval.historicalPath(start))
+	}
+}
+
+func TestContinuousCandles(t *testing.T) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	candle := func(minutes int, close string) CandleV3 {
+		rate := d(close)
+		return CandleV3{StartsAt: start.Add(time.Duration(minutes) * time.Minute), Open: rate, High: rate, Low: rate, Close: rate, Volume: d("1")}
+	}
+
+	// Unsorted, with a duplicate, a gap and candles out of bounds
+	series := continuousCandles([]CandleV3{
+		candle(15, "3"),
+		candle(0, "1"),
+		candle(-5, "0"),
+		candle(15, "9"),
+		candle(20, "4"),
+		candle(5, "2"),
+	}, 5*time.Minute, start, start.Add(20*time.Minute))
+	if assert.Len(t, series, 4) {
+		for i, want := range []string{"1", "2", "2", "3"} {
+			assert.True(t, series[i].StartsAt.Equal(start.Add(time.Duration(i)*5*time.Minute)))
+			assert.True(t, d(want).Equal(series[i].Close), "%d: %s", i, series[i].Close)
+		}
+		assert.True(t, series[2].Open.Equal(series[1].Close))
+		assert.True(t, series[2].Volume.IsZero())
+		assert.True(t, d("1").Equal(series[3].Volume))
+	}
+	assert.Empty(t, continuousCandles(nil, 5*time.Minute, start, start.Add(time.Hour)))
+}
@@ -1,13 +1,14 @@
 package bittrex
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/http/httputil"
 	"strconv"
@@ -16,97 +17,145 @@ import (
 )
 
 type client struct {
-	apiKey      string
-	apiSecret   string
-	httpClient  *http.Client
-	httpTimeout time.Duration
-	debug       bool
+	apiKey        string
+	apiSecret     string
+	httpClient    *http.Client
+	httpTimeout   time.Duration
+	debug         bool
+	retryPolicy   RetryPolicy
+	limits        map[EndpointClass]RateLimit // set by WithRateLimits, see newClient
+	limitPolicy   RateLimitPolicy
+	limiter       *rateLimiter
+	baseURL       string
+	legacyBaseURL string
+	wsHost        string
+	wsHub         string
+	wsTLSConfig   *tls.Config // nil uses the default transport and dialer
+	logger        Logger
+	clock         Clock
+	subaccountID  string          // scopes authenticated requests, see Bittrex.WithSubaccount
+	validator     *OrderValidator // checks orders before CreateOrder, see Bittrex.SetOrderValidator
+	refDataTTL    time.Duration
+	refData       *ReferenceData // shared by the subaccount handles, see Bittrex.ReferenceData
 }
 
 // NewClient return a new Bittrex HTTP client
 func NewClient(apiKey, apiSecret string) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, 30 * time.Second, false}
+	return newClient(apiKey, apiSecret)
 }
 
 // NewClientWithCustomHttpConfig returns a new Bittrex HTTP client using the predefined http client
 func NewClientWithCustomHttpConfig(apiKey, apiSecret string, httpClient *http.Client) (c *client) {
-	timeout := httpClient.Timeout
-	if timeout <= 0 {
-		timeout = 30 * time.Second
-	}
-	return &client{apiKey, apiSecret, httpClient, timeout, false}
+	return newClient(apiKey, apiSecret, WithHTTPClient(httpClient))
 }
 
 // NewClientWithCustomTimeout returns a new Bittrex HTTP client with custom timeout
 func NewClientWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, timeout, false}
+	return newClient(apiKey, apiSecret, WithTimeout(timeout))
 }
 
 func (c client) dumpRequest(r *http.Request) {
 	if r == nil {
-		log.Print("dumpReq ok: <nil>")
+		c.logger.Printf("dumpReq ok: <nil>")
 		return
 	}
 	dump, err := httputil.DumpRequest(r, true)
 	if err != nil {
-		log.Print("dumpReq err:", err)
+		c.logger.Printf("dumpReq err: %v", err)
 	} else {
-		log.Print("dumpReq ok:", string(dump))
+		c.logger.Printf("dumpReq ok: %v", string(dump))
 	}
 }
 
 func (c client) dumpResponse(r *http.Response) {
 	if r == nil {
-		log.Print("dumpResponse ok: <nil>")
+		c.logger.Printf("dumpResponse ok: <nil>")
 		return
 	}
 	dump, err := httputil.DumpResponse(r, true)
 	if err != nil {
-		log.Print("dumpResponse err:", err)
+		c.logger.Printf("dumpResponse err: %v", err)
 	} else {
-		log.Print("dumpResponse ok:", string(dump))
+		c.logger.Printf("dumpResponse ok: %v", string(dump))
 	}
 }
 
-// doTimeoutRequest do a HTTP request with timeout
-func (c *client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
+// doRequest do a HTTP request, bounded by the client timeout and the request context
+func (c *client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.debug {
+		c.dumpRequest(req)
 	}
-	done := make(chan result, 1)
-	go func() {
-		if c.debug {
-			c.dumpRequest(req)
+	resp, err := c.httpClient.Do(req)
+	if c.debug {
+		c.dumpResponse(resp)
+	}
+	return resp, err
+}
+
+// do prepare and process HTTP request to Bittrex API
+func (c *client) do(method string, resource string, payload string, authNeeded bool) (response []byte, err error) {
+	return c.doCtx(context.Background(), method, resource, payload, authNeeded)
+}
+
+// doCtx prepare and process HTTP request to Bittrex API. The request is
+// cancelled when ctx is done. GET requests are retried according to the retry policy.
+func (c *client) doCtx(ctx context.Context, method string, resource string, payload string, authNeeded bool) (response []byte, err error) {
+	response, _, err = c.doRetry(ctx, method, resource, payload, authNeeded, method == "GET")
+	return
+}
+
+// doHeaderCtx is doCtx also returning the response headers, e.g. the Sequence of order books.
+func (c *client) doHeaderCtx(ctx context.Context, method string, resource string, payload string, authNeeded bool) (response []byte, header http.Header, err error) {
+	return c.doRetry(ctx, method, resource, payload, authNeeded, method == "GET")
+}
+
+// doRetry runs the request once, or up to the retry policy attempts if idempotent is set
+// and the failure is transient. Each attempt is signed again.
+func (c *client) doRetry(ctx context.Context, method string, resource string, payload string, authNeeded bool, idempotent bool) (response []byte, header http.Header, err error) {
+	for attempt := 1; ; attempt++ {
+		response, header, err = c.doOnce(ctx, method, resource, payload, authNeeded)
+		if err == nil || !idempotent || attempt >= c.retryPolicy.MaxAttempts || ctx.Err() != nil {
+			return
+		}
+		retry, wait := shouldRetry(err, c.clock.Now())
+		if !retry {
+			return
+		}
+		if wait <= 0 {
+			wait = c.retryPolicy.backoff(attempt)
+		} else if max := c.retryPolicy.MaxBackoff; max > 0 && wait > max {
+			// Never trust the server with an unbounded wait
+			wait = max
 		}
-		resp, err := c.httpClient.Do(req)
 		if c.debug {
-			c.dumpResponse(resp)
+			c.logger.Printf("retrying %s %s in %s after: %v", method, resource, wait, err)
+		}
+		if sleepErr := sleepCtx(ctx, c.clock, wait); sleepErr != nil {
+			return
 		}
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		return nil, errors.New("timeout on reading data from Bittrex API")
 	}
 }
 
-// do prepare and process HTTP request to Bittrex API
-func (c *client) do(method string, resource string, payload string, authNeeded bool) (response []byte, err error) {
-	connectTimer := time.NewTimer(c.httpTimeout)
+// doOnce sends a single request to Bittrex API, bounded by the client timeout.
+func (c *client) doOnce(ctx context.Context, method string, resource string, payload string, authNeeded bool) (response []byte, header http.Header, err error) {
+	if err = c.limiter.wait(ctx, endpointClass(resource, authNeeded)); err != nil {
+		return
+	}
+
+	if c.httpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.httpTimeout)
+		defer cancel()
+	}
 
 	var rawurl string
 	if strings.HasPrefix(resource, "http") {
 		rawurl = resource
 	} else {
-		rawurl = fmt.Sprintf("%s%s/%s", API_BASE, API_VERSION, resource)
+		rawurl = c.baseURL + resource
 	}
 
-	req, err := http.NewRequest(method, rawurl, strings.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, method, rawurl, strings.NewReader(payload))
 	if err != nil {
 		return
 	}
@@ -127,10 +176,10 @@ func (c *client) do(method string, resource string, payload string, authNeeded b
 		payloadHash := hex.EncodeToString(payloadSum[:])
 
 		// Unix timestamp in milliseconds
-		nonce := time.Now().Unix() * 1000
+		nonce := c.clock.Now().Unix() * 1000
 
 		// All of the signature elemnts must be parsed as strings in this array.
-		preSignatura := []string{strconv.Itoa(int(nonce)), req.URL.String(), method, payloadHash}
+		preSignatura := []string{strconv.Itoa(int(nonce)), req.URL.String(), method, payloadHash, c.subaccountID}
 		signaturePayload := strings.Join(preSignatura, "")
 
 		mac := hmac.New(sha512.New, []byte(c.apiSecret))
@@ -142,21 +191,25 @@ func (c *client) do(method string, resource string, payload string, authNeeded b
 		req.Header.Add("Api-Timestamp", fmt.Sprintf("%d", nonce))
 		req.Header.Add("Api-Content-Hash", payloadHash)
 		req.Header.Add("Api-Signature", sig)
+		if c.subaccountID != "" {
+			req.Header.Add("Api-Subaccount-Id", c.subaccountID)
+		}
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return
 	}
 
 	defer resp.Body.Close()
+	header = resp.Header
 	response, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		return response, err
+		return response, header, err
 	}
 	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		err = errors.New(fmt.Sprintf("status: %v message:%s", resp.Status, string(response)))
+		err = newAPIError(req, resp, response)
 	}
-	return response, err
+	return response, header, err
 }
@@ -0,0 +1,91 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientDoCtxCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient("", "")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.doCtx(ctx, "GET", srv.URL+"/markets", "", false)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestClientDoCtxTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClientWithCustomTimeout("", "", 50*time.Millisecond)
+	c.retryPolicy = NoRetry
+	_, err := c.doCtx(context.Background(), "GET", srv.URL+"/markets", "", false)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestClientAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/funds":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"INSUFFICIENT_FUNDS","detail":"not enough BTC","data":{"currency":"BTC"}}`))
+		case "/throttled":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":"TOO_MANY_REQUESTS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("", "")
+	c.retryPolicy = NoRetry
+
+	_, err := c.do("POST", srv.URL+"/funds", "{}", false)
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, CODE_INSUFFICIENT_FUNDS, apiErr.Code)
+		assert.Equal(t, "not enough BTC", apiErr.Detail)
+		assert.JSONEq(t, `{"currency":"BTC"}`, string(apiErr.Data))
+		assert.Equal(t, "POST", apiErr.Method)
+	}
+	assert.True(t, IsInsufficientFunds(err))
+	assert.False(t, IsRateLimited(err))
+
+	_, err = c.do("GET", srv.URL+"/throttled", "", false)
+	assert.True(t, IsRateLimited(err))
+	assert.True(t, IsRateLimited(fmt.Errorf("wrapped: %w", err)))
+
+	_, err = c.do("GET", srv.URL+"/missing", "", false)
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "not json")
+}
@@ -0,0 +1,119 @@
+package bittrex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Types of the order referenced by OrderData
+const (
+	LINKED_ORDER             = "ORDER"
+	LINKED_CONDITIONAL_ORDER = "CONDITIONAL_ORDER"
+)
+
+// Statuses of a conditional order
+const (
+	CONDITIONAL_OPEN      = "OPEN"
+	CONDITIONAL_COMPLETED = "COMPLETED"
+	CONDITIONAL_CANCELLED = "CANCELLED"
+	CONDITIONAL_FAILED    = "FAILED"
+)
+
+// CreateConditionalOrderParams describes a conditional order.
+//
+// The order triggers when the last trade price of MarketSymbol is lower than or equal (LTE), or
+// greater than or equal (GTE), to TriggerPrice. With TrailingStopPercent instead, the trigger
+// price follows the market: TrailingStopPercent below its highest price for LTE, above its
+// lowest for GTE.
+//
+// When it triggers, OrderToCreate is placed and OrderToCancel, an order or another conditional
+// order, is cancelled. The link is reciprocal: a stop-loss linked to a take-profit limit order
+// is cancelled if the limit order closes first, which makes a one-cancels-the-other (OCO) pair.
+type CreateConditionalOrderParams struct {
+	MarketSymbol             string
+	Operand                  ConditionOperand
+	TriggerPrice             decimal.Decimal
+	TrailingStopPercent      decimal.Decimal
+	OrderToCreate            *CreateOrderParams
+	OrderToCancel            *OrderData
+	ClientConditionalOrderID string
+}
+
+// ConditionalOrderV3 is a conditional order. CreatedOrderID is the id of the order placed
+// when it triggered, OrderCreationErrorCode the reason it could not be when Status is FAILED.
+type ConditionalOrderV3 struct {
+	ID                       string           `json:"id"`
+	MarketSymbol             string           `json:"marketSymbol"`
+	Operand                  ConditionOperand `json:"operand"`
+	TriggerPrice             decimal.Decimal  `json:"triggerPrice"`
+	TrailingStopPercent      decimal.Decimal  `json:"trailingStopPercent"`
+	CreatedOrderID           string           `json:"createdOrderId"`
+	OrderToCreate            *OrderV3         `json:"orderToCreate"` // only the order parameters are set
+	OrderToCancel            *OrderData       `json:"orderToCancel"`
+	ClientConditionalOrderID string           `json:"clientConditionalOrderId"`
+	Status                   string           `json:"status"`
+	OrderCreationErrorCode   string           `json:"orderCreationErrorCode"`
+	CreatedAt                time.Time        `json:"createdAt"`
+	UpdatedAt                time.Time        `json:"updatedAt"`
+	ClosedAt                 time.Time        `json:"closedAt"`
+}
+
+// newConditionalOrder is the body of POST /conditional-orders.
+type newConditionalOrder struct {
+	MarketSymbol             string             `json:"marketSymbol"`
+	Operand                  ConditionOperand   `json:"operand"`
+	TriggerPrice             *decimal.Decimal   `json:"triggerPrice,omitempty"`
+	TrailingStopPercent      *decimal.Decimal   `json:"trailingStopPercent,omitempty"`
+	OrderToCreate            *CreateOrderParams `json:"orderToCreate,omitempty"`
+	OrderToCancel            *OrderData         `json:"orderToCancel,omitempty"`
+	ClientConditionalOrderID string             `json:"clientConditionalOrderId,omitempty"`
+}
+
+// conditionalOrderPayload checks params and builds the request body.
+func (b *Bittrex) conditionalOrderPayload(ctx context.Context, params CreateConditionalOrderParams) (p newConditionalOrder, err error) {
+	if params.MarketSymbol == "" {
+		return p, fmt.Errorf("%w: market is required", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if params.MarketSymbol, err = b.marketSymbol(ctx, params.MarketSymbol); err != nil {
+		return p, err
+	}
+	if params.Operand != LTE && params.Operand != GTE {
+		return p, fmt.Errorf("%w: operand must be LTE or GTE, got %q", ERR_ORDER_INVALID_PARAMETERS, params.Operand)
+	}
+	switch {
+	case params.TriggerPrice.IsPositive() == params.TrailingStopPercent.IsPositive():
+		return p, fmt.Errorf("%w: set either a trigger price or a trailing stop percent", ERR_ORDER_INVALID_PARAMETERS)
+	case params.TriggerPrice.IsPositive():
+		p.TriggerPrice = &params.TriggerPrice
+	case params.TrailingStopPercent.GreaterThanOrEqual(decimal.NewFromInt(100)):
+		return p, fmt.Errorf("%w: trailing stop percent must be lower than 100, got %s", ERR_ORDER_INVALID_PARAMETERS, params.TrailingStopPercent)
+	default:
+		p.TrailingStopPercent = &params.TrailingStopPercent
+	}
+	if params.OrderToCreate == nil && params.OrderToCancel == nil {
+		return p, fmt.Errorf("%w: set an order to create or an order to cancel", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if params.OrderToCreate != nil {
+		order, err := b.orderPayload(ctx, *params.OrderToCreate)
+		if err != nil {
+			return p, err
+		}
+		if order.MarketSymbol != params.MarketSymbol {
+			return p, fmt.Errorf("%w: order to create is on %s, not %s", ERR_ORDER_INVALID_PARAMETERS, order.MarketSymbol, params.MarketSymbol)
+		}
+		p.OrderToCreate = &order
+	}
+	if c := params.OrderToCancel; c != nil {
+		if c.ID == "" || c.Type != LINKED_ORDER && c.Type != LINKED_CONDITIONAL_ORDER {
+			return p, fmt.Errorf("%w: order to cancel must have an id and be an ORDER or a CONDITIONAL_ORDER", ERR_ORDER_INVALID_PARAMETERS)
+		}
+		p.OrderToCancel = c
+	}
+	p.MarketSymbol = params.MarketSymbol
+	p.Operand = params.Operand
+	p.ClientConditionalOrderID = params.ClientConditionalOrderID
+	return p, nil
+}
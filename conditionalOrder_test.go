@@ -0,0 +1,133 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalOrders(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("LTC", d("2"))
+
+	// Take profit at 0.005, stop loss at 0.003: one cancels the other
+	takeProfit, err := b.LimitSell("LTC-BTC", d("1"), d("0.005"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	stopLoss, err := b.CreateConditionalOrder(CreateConditionalOrderParams{
+		MarketSymbol: "LTC-BTC",
+		Operand:      LTE,
+		TriggerPrice: d("0.003"),
+		OrderToCreate: &CreateOrderParams{
+			MarketSymbol: "LTC-BTC",
+			Direction:    SELL,
+			Type:         MARKET,
+			Quantity:     d("1"),
+			TimeInForce:  IMMEDIATE_OR_CANCEL,
+		},
+		OrderToCancel: &OrderData{Type: LINKED_ORDER, ID: takeProfit.ID},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, CONDITIONAL_OPEN, stopLoss.Status)
+	assert.True(t, d("0.003").Equal(stopLoss.TriggerPrice))
+	if assert.NotNil(t, stopLoss.OrderToCreate) {
+		assert.True(t, d("1").Equal(stopLoss.OrderToCreate.Quantity))
+	}
+
+	open, err := b.GetOpenConditionalOrders("ltc-btc")
+	assert.Nil(t, err)
+	assert.Len(t, open, 1)
+
+	// Trades above the trigger leave it open, the first one below fires it
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0035"), d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0035"), d("1"))
+	stopLoss, err = b.GetConditionalOrder(stopLoss.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, CONDITIONAL_OPEN, stopLoss.Status)
+
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0029"), d("5"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0029"), d("1"))
+	stopLoss, err = b.GetConditionalOrder(stopLoss.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, CONDITIONAL_COMPLETED, stopLoss.Status)
+	created, err := b.GetOrder(stopLoss.CreatedOrderID)
+	assert.Nil(t, err)
+	assert.True(t, d("1").Equal(created.FillQuantity))
+	takeProfit, err = b.GetOrder(takeProfit.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "CLOSED", takeProfit.Status)
+	assert.True(t, d("1").Equal(srv.Balance("LTC").Total))
+
+	// Filling the linked order cancels a trailing stop
+	takeProfit, err = b.LimitSell("LTC-BTC", d("1"), d("0.004"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	trailing, err := b.CreateConditionalOrder(CreateConditionalOrderParams{
+		MarketSymbol:        "LTC-BTC",
+		Operand:             LTE,
+		TrailingStopPercent: d("10"),
+		OrderToCancel:       &OrderData{Type: LINKED_ORDER, ID: takeProfit.ID},
+	})
+	assert.Nil(t, err)
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.004"), d("1"))
+	trailing, err = b.GetConditionalOrder(trailing.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, CONDITIONAL_CANCELLED, trailing.Status)
+
+	closed, err := b.GetClosedConditionalOrders("all")
+	assert.Nil(t, err)
+	assert.Len(t, closed, 2)
+	_, err = b.CancelConditionalOrder(trailing.ID)
+	assert.Error(t, err)
+
+	_, err = b.CreateConditionalOrder(CreateConditionalOrderParams{
+		MarketSymbol:        "LTC-BTC",
+		Operand:             GTE,
+		TriggerPrice:        d("0.005"),
+		TrailingStopPercent: d("5"),
+		OrderToCancel:       &OrderData{Type: LINKED_ORDER, ID: takeProfit.ID},
+	})
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+}
+
+func TestConditionalOrderPayload(t *testing.T) {
+	_, b := newTestServer(t)
+	ctx := context.Background()
+	sell := &CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: SELL, Type: MARKET, Quantity: d("1"), TimeInForce: IMMEDIATE_OR_CANCEL}
+	linked := &OrderData{Type: LINKED_ORDER, ID: "order"}
+
+	for _, params := range []CreateConditionalOrderParams{
+		{Operand: LTE, TriggerPrice: d("0.003"), OrderToCreate: sell},
+		{MarketSymbol: "LTC", Operand: LTE, TriggerPrice: d("0.003"), OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: "LT", TriggerPrice: d("0.003"), OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, TriggerPrice: d("0.003"), TrailingStopPercent: d("5"), OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, TrailingStopPercent: d("100"), OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, TriggerPrice: d("0.003")},
+		{MarketSymbol: "ETH-BTC", Operand: LTE, TriggerPrice: d("0.003"), OrderToCreate: sell},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, TriggerPrice: d("0.003"), OrderToCancel: &OrderData{Type: LINKED_ORDER}},
+		{MarketSymbol: "LTC-BTC", Operand: LTE, TriggerPrice: d("0.003"), OrderToCancel: &OrderData{Type: "TRADE", ID: "order"}},
+	} {
+		_, err := b.conditionalOrderPayload(ctx, params)
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS), "%+v: %v", params, err)
+	}
+
+	// Symbols are resolved against the listed markets, only the trigger set is sent
+	p, err := b.conditionalOrderPayload(ctx, CreateConditionalOrderParams{
+		MarketSymbol:        "btc-ltc",
+		Operand:             GTE,
+		TrailingStopPercent: d("5"),
+		OrderToCreate:       &CreateOrderParams{MarketSymbol: "BTC-LTC", Direction: BUY, Type: MARKET, Quantity: d("1"), TimeInForce: IMMEDIATE_OR_CANCEL},
+		OrderToCancel:       linked,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "LTC-BTC", p.MarketSymbol)
+	assert.Nil(t, p.TriggerPrice)
+	if assert.NotNil(t, p.TrailingStopPercent) {
+		assert.True(t, d("5").Equal(*p.TrailingStopPercent))
+	}
+	if assert.NotNil(t, p.OrderToCreate) {
+		assert.Equal(t, "LTC-BTC", p.OrderToCreate.MarketSymbol)
+	}
+	assert.Equal(t, linked, p.OrderToCancel)
+}
@@ -29,4 +29,5 @@ type DepositV3 struct {
 type DepositHistoryParams struct {
 	Status         string `url:"status,omitempty"`
 	CurrencySymbol string `url:"currencySymbol,omitempty"`
+	PageParams            // closed deposits only
 }
@@ -46,4 +46,27 @@ const (
 	CANCELLED WithdrawalStatus = "CANCELLED"
 	ERROR_INVALID_ADDRESS WithdrawalStatus = "ERROR_INVALID_ADDRESS"
 	ALL WithdrawalStatus = ""
-)
\ No newline at end of file
+)
+
+type CandleType string
+
+const (
+	CANDLE_TRADE CandleType = "TRADE"
+	CANDLE_MIDPOINT CandleType = "MIDPOINT"
+)
+
+type CandleInterval string
+
+const (
+	MINUTE_1 CandleInterval = "MINUTE_1"
+	MINUTE_5 CandleInterval = "MINUTE_5"
+	HOUR_1 CandleInterval = "HOUR_1"
+	DAY_1 CandleInterval = "DAY_1"
+)
+
+type ConditionOperand string
+
+const (
+	LTE ConditionOperand = "LTE"
+	GTE ConditionOperand = "GTE"
+)
@@ -1,9 +1,127 @@
 package bittrex
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
 
-var(
-	ERR_ORDER_MISSING_PARAMETERS = errors.New("missing parameters. make sure (type, market_symbol, direction, time_in_force) are set")
+var (
+	ERR_ORDER_MISSING_PARAMETERS      = errors.New("missing parameters. make sure (type, market_symbol, direction, time_in_force) are set")
 	ERR_WITHDRAWAL_MISSING_PARAMETERS = errors.New("missing parameters. make sure (address, currency, quantity) are set")
+	ERR_ORDER_INVALID_PARAMETERS      = errors.New("invalid order parameters")
+	ERR_TRANSFER_MISSING_PARAMETERS   = errors.New("missing parameters. make sure (currency, amount, and either a subaccount or the master account) are set")
+
+	// Sentinels matched by APIError through errors.Is
+	ERR_INSUFFICIENT_FUNDS = errors.New("insufficient funds")
+	ERR_RATE_LIMITED       = errors.New("rate limited")
+	ERR_NOT_FOUND          = errors.New("not found")
+	ERR_UNAUTHORIZED       = errors.New("unauthorized")
+	ERR_MARKET_OFFLINE     = errors.New("market offline")
+)
+
+// Bittrex v3 error codes
+const (
+	CODE_INSUFFICIENT_FUNDS = "INSUFFICIENT_FUNDS"
+	CODE_NOT_FOUND          = "NOT_FOUND"
+	CODE_MARKET_OFFLINE     = "MARKET_OFFLINE"
+	CODE_INVALID_SIGNATURE  = "INVALID_SIGNATURE"
+	CODE_APIKEY_INVALID     = "APIKEY_INVALID"
+	CODE_TOO_MANY_REQUESTS  = "TOO_MANY_REQUESTS"
+	CODE_THROTTLED          = "THROTTLED"
 )
 
+// APIError is returned when Bittrex answers with a non-success HTTP status.
+// It carries the v3 error body ({"code", "detail", "data"}) along with the request that failed.
+type APIError struct {
+	StatusCode int             // HTTP status code, e.g. 400
+	Status     string          // HTTP status line, e.g. "400 Bad Request"
+	Code       string          // Bittrex error code, e.g. INSUFFICIENT_FUNDS
+	Detail     string          // optional human readable detail
+	Data       json.RawMessage // optional error specific data
+	Method     string          // HTTP method of the failed request
+	URL        string          // URL of the failed request
+	Header     http.Header     // response headers
+	Body       []byte          // raw response body
+}
+
+// newAPIError builds an APIError from a failed response and its body.
+// Bodies that are not a v3 error object are kept raw in Body.
+func newAPIError(req *http.Request, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Header:     resp.Header,
+		Body:       body,
+	}
+	e.decode(body)
+	return e
+}
+
+// decode fills the fields of the v3 error object found in body.
+func (e *APIError) decode(body []byte) {
+	var v3 struct {
+		Code   string          `json:"code"`
+		Detail string          `json:"detail"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(body, &v3) == nil {
+		e.Code, e.Detail, e.Data = v3.Code, v3.Detail, v3.Data
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status: %v message:%s", e.Status, string(e.Body))
+	}
+	if e.Detail == "" {
+		return fmt.Sprintf("status: %v code: %s", e.Status, e.Code)
+	}
+	return fmt.Sprintf("status: %v code: %s detail: %s", e.Status, e.Code, e.Detail)
+}
+
+// Is reports whether the error matches one of the package sentinels,
+// so that errors.Is(err, ERR_INSUFFICIENT_FUNDS) works on wrapped API errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ERR_INSUFFICIENT_FUNDS:
+		return e.Code == CODE_INSUFFICIENT_FUNDS
+	case ERR_RATE_LIMITED:
+		return e.StatusCode == http.StatusTooManyRequests || e.Code == CODE_TOO_MANY_REQUESTS || e.Code == CODE_THROTTLED
+	case ERR_NOT_FOUND:
+		return e.StatusCode == http.StatusNotFound || e.Code == CODE_NOT_FOUND
+	case ERR_UNAUTHORIZED:
+		return e.StatusCode == http.StatusUnauthorized || e.Code == CODE_INVALID_SIGNATURE || e.Code == CODE_APIKEY_INVALID
+	case ERR_MARKET_OFFLINE:
+		return e.Code == CODE_MARKET_OFFLINE
+	}
+	return false
+}
+
+// IsInsufficientFunds reports whether err is an API error caused by a lack of funds.
+func IsInsufficientFunds(err error) bool {
+	return errors.Is(err, ERR_INSUFFICIENT_FUNDS)
+}
+
+// IsRateLimited reports whether err is an API error caused by request throttling.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ERR_RATE_LIMITED)
+}
+
+// IsNotFound reports whether err is an API error for a missing resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ERR_NOT_FOUND)
+}
+
+// IsUnauthorized reports whether err is an API error caused by a bad API key or signature.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ERR_UNAUTHORIZED)
+}
+
+// IsMarketOffline reports whether err is an API error for a market that is not trading.
+func IsMarketOffline(err error) bool {
+	return errors.Is(err, ERR_MARKET_OFFLINE)
+}
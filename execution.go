@@ -0,0 +1,25 @@
+package bittrex
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ExecutionV3 is a fill of an order.
+type ExecutionV3 struct {
+	ID           string          `json:"id"`
+	MarketSymbol string          `json:"marketSymbol"`
+	ExecutedAt   time.Time       `json:"executedAt"`
+	Quantity     decimal.Decimal `json:"quantity"`
+	Rate         decimal.Decimal `json:"rate"`
+	OrderID      string          `json:"orderId"`
+	Commission   decimal.Decimal `json:"commission"`
+	IsTaker      bool            `json:"isTaker"`
+}
+
+// ExecutionsParams filters the executions.
+type ExecutionsParams struct {
+	MarketSymbol string `url:"marketSymbol,omitempty"`
+	PageParams
+}
@@ -0,0 +1,137 @@
+package bittrex
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// syncPageSize is the number of executions an ExecutionSync requests and hands over at once.
+const syncPageSize = 200
+
+// CursorStore persists the id of the last execution ingested by an ExecutionSync.
+type CursorStore interface {
+	// Load returns the saved cursor, empty if none was saved yet.
+	Load() (string, error)
+	// Save replaces the saved cursor.
+	Save(cursor string) error
+}
+
+// FileCursorStore is a CursorStore keeping the cursor in the file at its path.
+type FileCursorStore string
+
+// Load implements CursorStore. A missing file is an empty cursor.
+func (f FileCursorStore) Load() (string, error) {
+	b, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(b)), err
+}
+
+// Save implements CursorStore. The file is replaced atomically.
+func (f FileCursorStore) Save(cursor string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.WriteString(cursor + "\n"); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// ExecutionSync hands over the executions (fills) of the account that are newer than the
+// cursor of its store, oldest first, moving the cursor as they are ingested.
+type ExecutionSync struct {
+	b      *Bittrex
+	store  CursorStore
+	market string
+}
+
+// NewExecutionSync returns an ExecutionSync of the executions of market, or of every market if
+// market is empty. Its first Sync hands over the whole history unless store already has a
+// cursor: save the result of GetLastExecutionID in it to only ingest the fills to come.
+func (b *Bittrex) NewExecutionSync(store CursorStore, market string) *ExecutionSync {
+	return &ExecutionSync{b: b, store: store, market: normalizeSymbol(market)}
+}
+
+// Sync calls handle with the executions newer than the cursor, oldest first, in batches of up
+// to 200, and saves the id of the last execution of each batch once handle returns nil. It
+// stops at the first error, which it returns along with the number of executions ingested.
+//
+// The cursor is saved after handle returns, so a crash in between hands the batch over again
+// on the next Sync: for exactly-once ingestion, make handle idempotent on ExecutionV3.ID, or
+// save the cursor in the same transaction as the executions and pass a store reading it.
+func (s *ExecutionSync) Sync(ctx context.Context, handle func(executions []ExecutionV3) error) (n int, err error) {
+	cursor, err := s.store.Load()
+	if err != nil {
+		return
+	}
+
+	if cursor == "" {
+		// Walk the whole history, which is listed from the most recent
+		var history []ExecutionV3
+		it := s.b.ExecutionsIter(ctx, ExecutionsParams{MarketSymbol: s.market, PageParams: PageParams{PageSize: syncPageSize}})
+		for it.Next() {
+			history = append(history, it.Execution())
+		}
+		if err = it.Err(); err != nil {
+			return
+		}
+		reverseExecutions(history)
+		for len(history) > 0 {
+			batch := history
+			if len(batch) > syncPageSize {
+				batch = batch[:syncPageSize]
+			}
+			if err = s.ingest(batch, handle); err != nil {
+				return
+			}
+			n += len(batch)
+			history = history[len(batch):]
+		}
+		return
+	}
+
+	// Each page holds the executions following the cursor
+	for {
+		var page []ExecutionV3
+		page, err = s.b.GetExecutionsCtx(ctx, ExecutionsParams{
+			MarketSymbol: s.market,
+			PageParams:   PageParams{PreviousPageToken: cursor, PageSize: syncPageSize},
+		})
+		if err != nil || len(page) == 0 {
+			return
+		}
+		reverseExecutions(page)
+		if err = s.ingest(page, handle); err != nil {
+			return
+		}
+		n += len(page)
+		cursor = page[len(page)-1].ID
+	}
+}
+
+// ingest hands a batch over and saves its last execution as the cursor.
+func (s *ExecutionSync) ingest(batch []ExecutionV3, handle func([]ExecutionV3) error) error {
+	if err := handle(batch); err != nil {
+		return err
+	}
+	return s.store.Save(batch[len(batch)-1].ID)
+}
+
+func reverseExecutions(executions []ExecutionV3) {
+	for i, j := 0, len(executions)-1; i < j; i, j = i+1, j-1 {
+		executions[i], executions[j] = executions[j], executions[i]
+	}
+}
@@ -0,0 +1,53 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionSync(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.005"), d("10"))
+	_, err := b.MarketBuy("LTC-BTC", d("2"))
+	assert.Nil(t, err)
+
+	store := FileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+	sync := b.NewExecutionSync(store, "")
+	var ingested []ExecutionV3
+	ingest := func(executions []ExecutionV3) error {
+		ingested = append(ingested, executions...)
+		return nil
+	}
+
+	// The first sync ingests the history, oldest first
+	n, err := sync.Sync(context.Background(), ingest)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	if assert.Len(t, ingested, 2) {
+		assert.True(t, d("0.004").Equal(ingested[0].Rate))
+	}
+	n, err = sync.Sync(context.Background(), ingest)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+
+	// A failed batch is handed over again
+	_, err = b.MarketBuy("LTC-BTC", d("1"))
+	assert.Nil(t, err)
+	failure := errors.New("database down")
+	_, err = sync.Sync(context.Background(), func([]ExecutionV3) error { return failure })
+	assert.Equal(t, failure, err)
+	n, err = sync.Sync(context.Background(), ingest)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, ingested, 3)
+
+	cursor, err := store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, ingested[2].ID, cursor)
+}
@@ -0,0 +1,34 @@
+package bittrex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutions(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.005"), d("10"))
+
+	last, err := b.GetLastExecutionID()
+	assert.Nil(t, err)
+	assert.Empty(t, last)
+
+	// Two fills
+	order, err := b.MarketBuy("LTC-BTC", d("2"))
+	assert.Nil(t, err)
+	executions, err := b.GetExecutions(ExecutionsParams{MarketSymbol: "LTC-BTC"})
+	assert.Nil(t, err)
+	if assert.Len(t, executions, 2) {
+		assert.Equal(t, order.ID, executions[0].OrderID)
+		assert.True(t, d("0.005").Equal(executions[0].Rate))
+		execution, err := b.GetExecution(executions[1].ID)
+		assert.Nil(t, err)
+		assert.True(t, d("0.004").Equal(execution.Rate))
+		last, err = b.GetLastExecutionID()
+		assert.Nil(t, err)
+		assert.Equal(t, executions[0].ID, last)
+	}
+}
@@ -5,7 +5,7 @@ go 1.15
 require (
 	github.com/google/go-querystring v1.0.0
 	github.com/google/uuid v1.3.0
-	github.com/gorilla/websocket v1.4.2 // indirect
+	github.com/gorilla/websocket v1.4.2
 	github.com/joho/godotenv v1.3.0
 	github.com/shopspring/decimal v1.2.0
 	github.com/stretchr/testify v1.6.1
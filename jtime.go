@@ -19,7 +19,10 @@ func (jt *jTime) UnmarshalJSON(data []byte) error {
 	}
 	t, err := time.Parse(TIME_FORMAT, s)
 	if err != nil {
-		return err
+		// v3 payloads carry RFC 3339 timestamps
+		if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return err
+		}
 	}
 	jt.Time = t
 	return nil
@@ -0,0 +1,324 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// resyncBackoff paces the snapshots of a LocalOrderBook failing to synchronise.
+var resyncBackoff = RetryPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// LocalOrderBook is an L2 order book of a market kept up to date from the websocket.
+//
+// It loads a REST snapshot, applies the orderbook deltas in sequence order and loads a
+// new snapshot whenever a delta is missing or the stream reports a gap. Its methods are
+// safe for concurrent use.
+type LocalOrderBook struct {
+	b      *Bittrex
+	stream *Stream
+	market string
+	depth  int
+
+	release func() error // leaves the channel, shared with the other books of the market
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	resync chan struct{}
+
+	mu       sync.RWMutex
+	bids     []OrderbV3 // best (highest) rate first
+	asks     []OrderbV3 // best (lowest) rate first
+	sequence int
+	synced   bool
+	pending  []OrderbookUpdate // deltas received while loading a snapshot
+	waiters  chan struct{}     // closed and replaced whenever the book gets synced
+}
+
+// WatchOrderBook maintains a local order book of market from the orderbook channel of stream,
+// which books of the same market and depth share.
+// depth is 1, 25 or 500. It returns once the book is synchronised, or ctx.Err() if ctx is done first.
+func (b *Bittrex) WatchOrderBook(ctx context.Context, stream *Stream, market string, depth int) (*LocalOrderBook, error) {
+	market, err := b.cachedMarketSymbol(market)
+	if err != nil {
+		return nil, err
+	}
+	book := &LocalOrderBook{
+		b:       b,
+		stream:  stream,
+		market:  market,
+		depth:   depth,
+		resync:  make(chan struct{}, 1),
+		waiters: make(chan struct{}),
+	}
+	book.ctx, book.cancel = context.WithCancel(context.Background())
+
+	if book.release, err = stream.share(ctx, OrderBookChannel(market, depth), book.handle); err != nil {
+		book.cancel()
+		return nil, err
+	}
+	go book.run()
+	book.requestResync()
+
+	if err := book.WaitSynced(ctx); err != nil {
+		book.Close()
+		return nil, err
+	}
+	return book, nil
+}
+
+// Close stops maintaining the book. The stream unsubscribes from its channel once no other
+// book of the market and depth uses it.
+func (l *LocalOrderBook) Close() error {
+	l.cancel()
+	return l.release()
+}
+
+// Market returns the market of the book.
+func (l *LocalOrderBook) Market() string {
+	return l.market
+}
+
+// WaitSynced blocks until the book is synchronised, or returns ctx.Err() once ctx is done.
+func (l *LocalOrderBook) WaitSynced(ctx context.Context) error {
+	for {
+		l.mu.RLock()
+		synced, waiters := l.synced, l.waiters
+		l.mu.RUnlock()
+		if synced {
+			return nil
+		}
+		select {
+		case <-waiters:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Synced reports whether the book reflects the market. It is false while a snapshot is loading.
+func (l *LocalOrderBook) Synced() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.synced
+}
+
+// Sequence returns the sequence number of the last delta applied.
+func (l *LocalOrderBook) Sequence() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.sequence
+}
+
+// BestBid returns the highest bid. ok is false if there is none.
+func (l *LocalOrderBook) BestBid() (bid OrderbV3, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.bids) == 0 {
+		return
+	}
+	return l.bids[0], true
+}
+
+// BestAsk returns the lowest ask. ok is false if there is none.
+func (l *LocalOrderBook) BestAsk() (ask OrderbV3, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.asks) == 0 {
+		return
+	}
+	return l.asks[0], true
+}
+
+// Bids returns up to n bids, best first. n <= 0 returns every level.
+func (l *LocalOrderBook) Bids(n int) []OrderbV3 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return levels(l.bids, n)
+}
+
+// Asks returns up to n asks, best first. n <= 0 returns every level.
+func (l *LocalOrderBook) Asks(n int) []OrderbV3 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return levels(l.asks, n)
+}
+
+// Snapshot returns a copy of the book.
+func (l *LocalOrderBook) Snapshot() OrderBookV3 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return OrderBookV3{Bid: levels(l.bids, 0), Ask: levels(l.asks, 0)}
+}
+
+func levels(side []OrderbV3, n int) []OrderbV3 {
+	if n <= 0 || n > len(side) {
+		n = len(side)
+	}
+	return append([]OrderbV3(nil), side[:n]...)
+}
+
+// handle receives the messages of the orderbook channel.
+func (l *LocalOrderBook) handle(method string, payload json.RawMessage) {
+	if method == STREAM_GAP {
+		l.mu.Lock()
+		l.invalidate()
+		l.mu.Unlock()
+		l.requestResync()
+		return
+	}
+
+	var u OrderbookUpdate
+	if err := json.Unmarshal(payload, &u); err != nil {
+		l.b.client.logger.Printf("orderbook Unmarshal err: %s %s", err.Error(), l.market)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case !l.synced:
+		l.pending = append(l.pending, u)
+	case u.Sequence <= l.sequence:
+		// Already part of the snapshot
+	case u.Sequence == l.sequence+1:
+		l.apply(u)
+	default:
+		l.b.client.logger.Printf("orderbook %s: sequence gap %d -> %d, resyncing", l.market, l.sequence, u.Sequence)
+		l.invalidate()
+		l.pending = append(l.pending, u)
+		l.requestResync()
+	}
+}
+
+// invalidate marks the book out of sync. l.mu must be held.
+func (l *LocalOrderBook) invalidate() {
+	l.synced = false
+	l.pending = nil
+}
+
+func (l *LocalOrderBook) requestResync() {
+	select {
+	case l.resync <- struct{}{}:
+	default:
+	}
+}
+
+// run loads a snapshot whenever one is requested, until Close.
+func (l *LocalOrderBook) run() {
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-l.resync:
+		}
+
+		for attempt := 1; !l.load(); attempt++ {
+			if sleepCtx(l.ctx, l.b.client.clock, resyncBackoff.backoff(attempt)) != nil {
+				return
+			}
+		}
+	}
+}
+
+// load fetches a snapshot and replays the pending deltas following it.
+// It returns false if it must be retried.
+func (l *LocalOrderBook) load() bool {
+	snapshot, sequence, err := l.b.GetOrderBookWithSequenceCtx(l.ctx, l.market, int32(l.depth))
+	if err != nil {
+		if l.ctx.Err() == nil {
+			l.b.client.logger.Printf("orderbook %s: snapshot error: %s", l.market, err)
+		}
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.synced {
+		return true
+	}
+
+	sort.SliceStable(l.pending, func(i, j int) bool { return l.pending[i].Sequence < l.pending[j].Sequence })
+	next := sequence + 1
+	for _, u := range l.pending {
+		if u.Sequence < next {
+			continue
+		}
+		if u.Sequence > next {
+			// The snapshot is older than the deltas received, or a delta is missing:
+			// a later snapshot will cover it
+			return false
+		}
+		next++
+	}
+
+	l.bids = sortLevels(snapshot.Bid, true)
+	l.asks = sortLevels(snapshot.Ask, false)
+	l.sequence = sequence
+	for _, u := range l.pending {
+		if u.Sequence == l.sequence+1 {
+			l.apply(u)
+		}
+	}
+	l.pending = nil
+	l.synced = true
+	close(l.waiters)
+	l.waiters = make(chan struct{})
+	return true
+}
+
+func sortLevels(side []OrderbV3, descending bool) []OrderbV3 {
+	sorted := append([]OrderbV3(nil), side...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Rate.GreaterThan(sorted[j].Rate)
+		}
+		return sorted[i].Rate.LessThan(sorted[j].Rate)
+	})
+	return sorted
+}
+
+// apply applies a delta. l.mu must be held.
+func (l *LocalOrderBook) apply(u OrderbookUpdate) {
+	for _, d := range u.BidDeltas {
+		l.bids = updateLevel(l.bids, d, true)
+	}
+	for _, d := range u.AskDeltas {
+		l.asks = updateLevel(l.asks, d, false)
+	}
+	l.sequence = u.Sequence
+}
+
+// updateLevel sets the quantity of a price level, removing it when the quantity is zero.
+func updateLevel(side []OrderbV3, d OrderbV3, descending bool) []OrderbV3 {
+	i := sort.Search(len(side), func(i int) bool {
+		if descending {
+			return side[i].Rate.LessThanOrEqual(d.Rate)
+		}
+		return side[i].Rate.GreaterThanOrEqual(d.Rate)
+	})
+	found := i < len(side) && side[i].Rate.Equal(d.Rate)
+
+	switch {
+	case d.Quantity.LessThanOrEqual(decimal.Zero):
+		if found {
+			side = append(side[:i], side[i+1:]...)
+		}
+	case found:
+		side[i].Quantity = d.Quantity
+	default:
+		side = append(side, OrderbV3{})
+		copy(side[i+1:], side[i:])
+		side[i] = d
+	}
+	return side
+}
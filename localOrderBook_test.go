@@ -0,0 +1,155 @@
+package bittrex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func newTestLocalOrderBook(t *testing.T, opts ...StreamOption) (*bittrextest.Server, *bittrextest.Hub, *LocalOrderBook) {
+	srv := bittrextest.NewServer("key", "secret")
+	t.Cleanup(srv.Close)
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0041"), d("5"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("2"))
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0039"), d("3"))
+
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()))
+	stream, err := b.OpenStream(context.Background(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stream.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	book, err := b.WatchOrderBook(ctx, stream, "LTC-BTC", 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { book.Close() })
+	return srv, hub, book
+}
+
+// waitSequence waits for the book to reach sequence, synchronised.
+func waitSequence(t *testing.T, book *LocalOrderBook, sequence int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !(book.Synced() && book.Sequence() == sequence) {
+		if time.Now().After(deadline) {
+			t.Fatalf("book at sequence %d, synced %v, expected %d", book.Sequence(), book.Synced(), sequence)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLocalOrderBookDeltas(t *testing.T) {
+	_, hub, book := newTestLocalOrderBook(t)
+	seq := book.Sequence()
+
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.True(t, d("0.0039").Equal(bid.Rate))
+	assert.Len(t, book.Asks(0), 2)
+
+	// Remove the best bid, add two levels, resize an ask
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{
+		MarketSymbol: "LTC-BTC",
+		Depth:        25,
+		Sequence:     int64(seq + 1),
+		BidDeltas: []bittrextest.OrderBookEntry{
+			{Rate: d("0.0039"), Quantity: decimal.Zero},
+			{Rate: d("0.0037"), Quantity: d("4")},
+			{Rate: d("0.0038"), Quantity: d("1")},
+		},
+		AskDeltas: []bittrextest.OrderBookEntry{{Rate: d("0.0041"), Quantity: d("1")}},
+	}))
+	waitSequence(t, book, seq+1)
+
+	bids := book.Bids(0)
+	if assert.Len(t, bids, 2) {
+		assert.True(t, d("0.0038").Equal(bids[0].Rate))
+		assert.True(t, d("0.0037").Equal(bids[1].Rate))
+	}
+	asks := book.Asks(1)
+	if assert.Len(t, asks, 1) {
+		assert.True(t, d("0.004").Equal(asks[0].Rate))
+	}
+	assert.True(t, d("1").Equal(book.Snapshot().Ask[1].Quantity))
+
+	// Replayed deltas are ignored
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{
+		MarketSymbol: "LTC-BTC",
+		Depth:        25,
+		Sequence:     int64(seq + 1),
+		BidDeltas:    []bittrextest.OrderBookEntry{{Rate: d("0.0038"), Quantity: decimal.Zero}},
+	}))
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{MarketSymbol: "LTC-BTC", Depth: 25, Sequence: int64(seq + 2)}))
+	waitSequence(t, book, seq+2)
+	assert.Len(t, book.Bids(0), 2)
+}
+
+func TestLocalOrderBookSequenceGap(t *testing.T) {
+	srv, hub, book := newTestLocalOrderBook(t)
+	seq := book.Sequence()
+
+	// A missing delta makes the book reload a snapshot covering it
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{MarketSymbol: "LTC-BTC", Depth: 25, Sequence: int64(seq + 2)}))
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0038"), d("1"))
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0037"), d("1"))
+	waitSequence(t, book, seq+2)
+	assert.Len(t, book.Bids(0), 3)
+}
+
+func TestLocalOrderBookStreamGap(t *testing.T) {
+	srv, hub, book := newTestLocalOrderBook(t, WithReconnect(RetryPolicy{InitialBackoff: 10 * time.Millisecond}))
+	seq := book.Sequence()
+
+	// Deltas published while disconnected are lost, the book reloads after the reconnection
+	srv.AddLiquidity("LTC-BTC", "BUY", d("0.0038"), d("1"))
+	hub.Disconnect()
+	waitSequence(t, book, seq+1)
+	assert.Len(t, book.Bids(0), 2)
+}
+
+func TestLocalOrderBookSharedChannel(t *testing.T) {
+	_, hub, book := newTestLocalOrderBook(t)
+	seq := book.Sequence()
+	unsubscribes := func() int {
+		n := 0
+		for _, c := range hub.Calls() {
+			if c.Method == "Unsubscribe" {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Closing a second book of the market leaves the first one subscribed
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	other, err := book.b.WatchOrderBook(ctx, book.stream, "LTC-BTC", 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, other.Close())
+	assert.Equal(t, 0, unsubscribes())
+
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{MarketSymbol: "LTC-BTC", Depth: 25, Sequence: int64(seq + 1)}))
+	waitSequence(t, book, seq+1)
+
+	// The last book leaves the channel
+	assert.Nil(t, book.Close())
+	assert.Equal(t, 1, unsubscribes())
+}
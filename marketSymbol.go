@@ -0,0 +1,151 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ERR_INVALID_MARKET_SYMBOL is returned for a market symbol that is not two currencies
+// separated by a dash. It wraps ERR_ORDER_INVALID_PARAMETERS.
+var ERR_INVALID_MARKET_SYMBOL = fmt.Errorf("%w: invalid market symbol", ERR_ORDER_INVALID_PARAMETERS)
+
+// MarketSymbol is a v3 market symbol, BASE-QUOTE: LTC-BTC trades LTC against BTC. The v1 and
+// v2 APIs wrote it the other way round, QUOTE-BASE (BTC-LTC).
+//
+// Market parameters are strings parsed the same way by every REST and websocket entry point,
+// so a MarketSymbol, a string constant or a lowercase string can be passed alike. A symbol
+// is converted from legacy notation when only its conversion is a listed market: order
+// creations and cancellations load the reference data to check it, the other entry points
+// only check it once loaded. A malformed symbol fails with ERR_INVALID_MARKET_SYMBOL.
+type MarketSymbol string
+
+// NewMarketSymbol returns the symbol of the market trading base against quote.
+func NewMarketSymbol(base, quote string) MarketSymbol {
+	return MarketSymbol(normalizeSymbol(base) + "-" + normalizeSymbol(quote))
+}
+
+// ParseMarketSymbol parses a v3 symbol, e.g. LTC-BTC or ltc-btc, or ERR_INVALID_MARKET_SYMBOL.
+func ParseMarketSymbol(s string) (MarketSymbol, error) {
+	parts := strings.Split(normalizeSymbol(s), "-")
+	if len(parts) != 2 || !isCurrencySymbol(parts[0]) || !isCurrencySymbol(parts[1]) {
+		return "", fmt.Errorf("%w: %q", ERR_INVALID_MARKET_SYMBOL, s)
+	}
+	return NewMarketSymbol(parts[0], parts[1]), nil
+}
+
+// ParseLegacyMarketSymbol parses a v1/v2 symbol, QUOTE-BASE: BTC-LTC is LTC-BTC.
+func ParseLegacyMarketSymbol(s string) (MarketSymbol, error) {
+	legacy, err := ParseMarketSymbol(s)
+	if err != nil {
+		return "", err
+	}
+	return NewMarketSymbol(legacy.Quote(), legacy.Base()), nil
+}
+
+// Base returns the currency traded, LTC for LTC-BTC.
+func (m MarketSymbol) Base() string {
+	base, _ := m.split()
+	return base
+}
+
+// Quote returns the currency prices are expressed in, BTC for LTC-BTC.
+func (m MarketSymbol) Quote() string {
+	_, quote := m.split()
+	return quote
+}
+
+// Legacy returns the symbol in v1/v2 notation, BTC-LTC for LTC-BTC.
+func (m MarketSymbol) Legacy() string {
+	base, quote := m.split()
+	return quote + "-" + base
+}
+
+// Valid reports whether m is a well formed v3 symbol. It may still not be listed, which
+// ReferenceData.ValidateMarket checks.
+func (m MarketSymbol) Valid() bool {
+	_, err := ParseMarketSymbol(string(m))
+	return err == nil
+}
+
+func (m MarketSymbol) String() string {
+	return string(m)
+}
+
+func (m MarketSymbol) split() (base, quote string) {
+	parts := strings.SplitN(normalizeSymbol(string(m)), "-", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// ValidateMarket returns the listed market of symbol, which may be in v3 or legacy notation,
+// or ERR_INVALID_MARKET_SYMBOL, or ERR_NOT_FOUND if neither notation is listed.
+func (r *ReferenceData) ValidateMarket(ctx context.Context, symbol string) (MarketSymbol, error) {
+	m, err := ParseMarketSymbol(symbol)
+	if err != nil {
+		return "", err
+	}
+	if _, err = r.Market(ctx, string(m)); !errors.Is(err, ERR_NOT_FOUND) {
+		return m, err
+	}
+	legacy, _ := ParseLegacyMarketSymbol(symbol)
+	if _, legacyErr := r.Market(ctx, string(legacy)); legacyErr == nil {
+		return legacy, nil
+	}
+	return "", err
+}
+
+// resolveMarketSymbol parses a market symbol given to an entry point. A symbol whose
+// notation is not listed but whose legacy conversion is, BTC-LTC for LTC-BTC, is converted.
+// Symbols listed in neither notation are kept as given: the notation is never guessed.
+func resolveMarketSymbol(s string, listed func(MarketSymbol) bool) (MarketSymbol, error) {
+	m, err := ParseMarketSymbol(s)
+	if err != nil {
+		return "", err
+	}
+	if legacy := NewMarketSymbol(m.Quote(), m.Base()); !listed(m) && listed(legacy) {
+		return legacy, nil
+	}
+	return m, nil
+}
+
+// marketSymbol resolves a market symbol given to a trading entry point, loading the markets
+// of the reference data cache first if needed, so an order never goes to a market guessed.
+func (b *Bittrex) marketSymbol(ctx context.Context, s string) (string, error) {
+	if _, err := ParseMarketSymbol(s); err != nil {
+		return "", err
+	}
+	if err := b.client.refData.loadMarkets(ctx, false); err != nil {
+		return "", err
+	}
+	m, err := resolveMarketSymbol(s, b.client.refData.listed)
+	return string(m), err
+}
+
+// cachedMarketSymbol resolves a market symbol given to a market data or websocket entry
+// point against the markets of the reference data cache, without loading them: a legacy
+// symbol is only converted once they are loaded.
+func (b *Bittrex) cachedMarketSymbol(s string) (string, error) {
+	m, err := resolveMarketSymbol(s, b.client.refData.listed)
+	return string(m), err
+}
+
+// normalizeSymbol trims and upper cases a market or currency symbol given to an entry point.
+func normalizeSymbol(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+func isCurrencySymbol(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
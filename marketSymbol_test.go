@@ -0,0 +1,101 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketSymbol(t *testing.T) {
+	m, err := ParseMarketSymbol(" ltc-btc ")
+	assert.Nil(t, err)
+	assert.Equal(t, MarketSymbol("LTC-BTC"), m)
+	assert.Equal(t, "LTC", m.Base())
+	assert.Equal(t, "BTC", m.Quote())
+	assert.Equal(t, "BTC-LTC", m.Legacy())
+	assert.True(t, m.Valid())
+	assert.Equal(t, m, NewMarketSymbol("ltc", "BTC"))
+
+	m, err = ParseLegacyMarketSymbol("BTC-CRW")
+	assert.Nil(t, err)
+	assert.Equal(t, MarketSymbol("CRW-BTC"), m)
+
+	for _, invalid := range []string{"", "LTCBTC", "LTC-", "-BTC", "LTC-BTC-ETH", "LTC_BTC"} {
+		_, err = ParseMarketSymbol(invalid)
+		assert.True(t, errors.Is(err, ERR_INVALID_MARKET_SYMBOL), invalid)
+		assert.False(t, MarketSymbol(invalid).Valid())
+	}
+
+	// Entry points take any case
+	assert.Equal(t, "orderbook_LTC-BTC_25", OrderBookChannel("ltc-btc", 25))
+
+	// Only a listed conversion turns a symbol around
+	listed := func(m MarketSymbol) bool { return m == "LTC-BTC" }
+	for symbol, want := range map[string]string{"btc-ltc": "LTC-BTC", "LTC-BTC": "LTC-BTC", "USDT-XYZ": "USDT-XYZ", "BTC-ETH": "BTC-ETH"} {
+		m, err = resolveMarketSymbol(symbol, listed)
+		assert.Nil(t, err)
+		assert.Equal(t, MarketSymbol(want), m, symbol)
+	}
+}
+
+func TestMarketSymbolEntryPoints(t *testing.T) {
+	srv := bittrextest.NewServer("key", "secret")
+	defer srv.Close()
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	srv.AddMarket(bittrextest.Market{Symbol: "USDT-XYZ", BaseCurrencySymbol: "USDT", QuoteCurrencySymbol: "XYZ", Precision: 8})
+	srv.SetBalance("BTC", d("1"))
+	srv.SetBalance("XYZ", d("10"))
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry))
+
+	// Market data entry points do not load the markets to convert legacy symbols
+	_, err := b.GetOrderBook("BTC-LTC", 25, "both")
+	assert.True(t, IsNotFound(err))
+
+	// Orders do, and a v3 symbol is never turned around on a guess
+	order, err := b.CreateOrder(CreateOrderParams{MarketSymbol: "USDT-XYZ", Direction: BUY, Type: LIMIT, Quantity: d("1"), Limit: d("1"), TimeInForce: GOOD_TIL_CANCELLED})
+	assert.Nil(t, err)
+	assert.Equal(t, "USDT-XYZ", order.MarketSymbol)
+	order, err = b.CreateOrder(CreateOrderParams{MarketSymbol: "BTC-LTC", Direction: BUY, Type: LIMIT, Quantity: d("1"), Limit: d("0.004"), TimeInForce: GOOD_TIL_CANCELLED})
+	assert.Nil(t, err)
+	assert.Equal(t, "LTC-BTC", order.MarketSymbol)
+
+	// Once loaded, every entry point converts them
+	open, err := b.GetOpenOrders("btc-ltc")
+	assert.Nil(t, err)
+	assert.Len(t, open, 1)
+	_, err = b.GetOrderBook("BTC-LTC", 25, "both")
+	assert.Nil(t, err)
+
+	// Malformed symbols are rejected before any request
+	_, err = b.GetOrderBook("LTCBTC", 25, "both")
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	_, err = b.GetOpenOrders("LTC_BTC")
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	_, err = b.CreateOrder(CreateOrderParams{MarketSymbol: "LTC-BTC-ETH", Direction: BUY, Type: MARKET, Quantity: d("1"), TimeInForce: IMMEDIATE_OR_CANCEL})
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	assert.True(t, errors.Is(err, ERR_INVALID_MARKET_SYMBOL))
+	_, err = NewOrder("LTC-").Buy().Quantity(d("1")).Build()
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+}
+
+func TestReferenceDataValidateMarket(t *testing.T) {
+	srv := bittrextest.NewServer("key", "secret")
+	defer srv.Close()
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	ref := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry)).ReferenceData()
+	ctx := context.Background()
+
+	m, err := ref.ValidateMarket(ctx, "ltc-btc")
+	assert.Nil(t, err)
+	assert.Equal(t, MarketSymbol("LTC-BTC"), m)
+	m, err = ref.ValidateMarket(ctx, "BTC-LTC")
+	assert.Nil(t, err)
+	assert.Equal(t, MarketSymbol("LTC-BTC"), m)
+	_, err = ref.ValidateMarket(ctx, "DOGE-BTC")
+	assert.True(t, IsNotFound(err))
+	_, err = ref.ValidateMarket(ctx, "DOGEBTC")
+	assert.True(t, errors.Is(err, ERR_INVALID_MARKET_SYMBOL))
+}
@@ -1,14 +1,132 @@
 package bittrex
 
-// NewOrder struct
-type NewOrder struct {
-	MarketSymbol  string `json:"marketSymbol"`
-	Direction     string `json:"direction"`
-	Type          string `json:"type"`
-	Quantity      string `json:"quantity"`
-	Ceiling       string `json:"ceiling,omitempty"`
-	Limit         string `json:"limit"`
-	TimeInForce   string `json:"timeInForce"`
-	ClientOrderID string `json:"clientOrderId,omitempty"`
-	UseAwards     bool   `json:"useAwards"`
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderBuilder builds CreateOrderParams step by step. Each step returns a copy, so a partly
+// built order can be reused as a template:
+//
+//	params, err := bittrex.NewOrder("LTC-BTC").Buy().Limit(rate).Quantity(quantity).PostOnly().ClientID(id).Build()
+//
+// The order type follows the amounts set: LIMIT with a limit, CEILING_MARKET with a ceiling,
+// CEILING_LIMIT with both, MARKET otherwise. The time in force defaults to GOOD_TIL_CANCELLED
+// for LIMIT orders and IMMEDIATE_OR_CANCEL for the others.
+type OrderBuilder struct {
+	params CreateOrderParams
+}
+
+// NewOrder starts building an order on market.
+func NewOrder(market string) OrderBuilder {
+	return OrderBuilder{params: CreateOrderParams{MarketSymbol: normalizeSymbol(market)}}
+}
+
+// Buy makes the order a buy.
+func (o OrderBuilder) Buy() OrderBuilder {
+	o.params.Direction = BUY
+	return o
+}
+
+// Sell makes the order a sell.
+func (o OrderBuilder) Sell() OrderBuilder {
+	o.params.Direction = SELL
+	return o
+}
+
+// Quantity sets the quantity of base currency to trade.
+func (o OrderBuilder) Quantity(quantity decimal.Decimal) OrderBuilder {
+	o.params.Quantity = quantity
+	return o
+}
+
+// Limit sets the worst price the order trades at.
+func (o OrderBuilder) Limit(rate decimal.Decimal) OrderBuilder {
+	o.params.Limit = rate
+	return o
+}
+
+// Ceiling sets the amount of quote currency a ceiling order spends at most.
+func (o OrderBuilder) Ceiling(ceiling decimal.Decimal) OrderBuilder {
+	o.params.Ceiling = ceiling
+	return o
+}
+
+// TimeInForce sets the time in force.
+func (o OrderBuilder) TimeInForce(timeInForce TimeInForce) OrderBuilder {
+	o.params.TimeInForce = timeInForce
+	return o
+}
+
+// PostOnly makes a LIMIT order rest on the book as a maker, or be rejected.
+func (o OrderBuilder) PostOnly() OrderBuilder {
+	return o.TimeInForce(POST_ONLY_GOOD_TIL_CANCELLED)
+}
+
+// ImmediateOrCancel cancels what the order does not fill at once.
+func (o OrderBuilder) ImmediateOrCancel() OrderBuilder {
+	return o.TimeInForce(IMMEDIATE_OR_CANCEL)
+}
+
+// FillOrKill cancels the order unless it fills entirely at once.
+func (o OrderBuilder) FillOrKill() OrderBuilder {
+	return o.TimeInForce(FILL_OR_KILL)
+}
+
+// ClientID sets the client order id, which makes the creation safe to retry.
+func (o OrderBuilder) ClientID(clientOrderID string) OrderBuilder {
+	o.params.ClientOrderID = clientOrderID
+	return o
+}
+
+// Build returns the params of the order, or ERR_ORDER_INVALID_PARAMETERS if the market is
+// malformed, or the order type lacks an amount, or does not accept its direction or time in force.
+func (o OrderBuilder) Build() (CreateOrderParams, error) {
+	p := o.params
+	switch {
+	case p.Ceiling.IsZero() && p.Limit.IsZero():
+		p.Type = MARKET
+	case p.Ceiling.IsZero():
+		p.Type = LIMIT
+	case p.Limit.IsZero():
+		p.Type = CEILING_MARKET
+	default:
+		p.Type = CEILING_LIMIT
+	}
+	if p.TimeInForce == "" {
+		p.TimeInForce = IMMEDIATE_OR_CANCEL
+		if p.Type == LIMIT {
+			p.TimeInForce = GOOD_TIL_CANCELLED
+		}
+	}
+
+	if p.MarketSymbol == "" {
+		return p, fmt.Errorf("%w: market is required", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if _, err := ParseMarketSymbol(p.MarketSymbol); err != nil {
+		return p, err
+	}
+	if p.Direction == "" {
+		return p, fmt.Errorf("%w: call Buy or Sell", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if p.Type == MARKET || p.Type == LIMIT {
+		if !p.Quantity.IsPositive() {
+			return p, fmt.Errorf("%w: %s orders need a positive quantity", ERR_ORDER_INVALID_PARAMETERS, p.Type)
+		}
+	} else {
+		if p.Direction != BUY {
+			return p, fmt.Errorf("%w: %s orders can only buy", ERR_ORDER_INVALID_PARAMETERS, p.Type)
+		}
+		if !p.Quantity.IsZero() {
+			return p, fmt.Errorf("%w: %s orders take a ceiling, not a quantity", ERR_ORDER_INVALID_PARAMETERS, p.Type)
+		}
+	}
+	if p.Limit.IsNegative() || p.Ceiling.IsNegative() {
+		return p, fmt.Errorf("%w: limit and ceiling must be positive", ERR_ORDER_INVALID_PARAMETERS)
+	}
+	if !timeInForceAllowed(p.Type, p.TimeInForce) {
+		return p, fmt.Errorf("%w: %s orders do not accept %s", ERR_ORDER_INVALID_PARAMETERS, p.Type, p.TimeInForce)
+	}
+	return p, nil
 }
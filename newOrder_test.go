@@ -0,0 +1,56 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBuilder(t *testing.T) {
+	ltc := NewOrder("ltc-btc")
+
+	params, err := ltc.Buy().Limit(d("0.00312345")).Quantity(d("2")).PostOnly().ClientID("id").Build()
+	assert.Nil(t, err)
+	assert.Equal(t, CreateOrderParams{
+		MarketSymbol:  "LTC-BTC",
+		Direction:     BUY,
+		Type:          LIMIT,
+		Quantity:      d("2"),
+		Limit:         d("0.00312345"),
+		TimeInForce:   POST_ONLY_GOOD_TIL_CANCELLED,
+		ClientOrderID: "id",
+	}, params)
+	body, err := json.Marshal(params)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"marketSymbol":"LTC-BTC","direction":"BUY","type":"LIMIT","quantity":"2","limit":"0.00312345","timeInForce":"POST_ONLY_GOOD_TIL_CANCELLED","clientOrderId":"id"}`, string(body))
+
+	params, err = ltc.Sell().Quantity(d("1")).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, MARKET, params.Type)
+	assert.Equal(t, IMMEDIATE_OR_CANCEL, params.TimeInForce)
+
+	params, err = ltc.Buy().Ceiling(d("0.1")).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, CEILING_MARKET, params.Type)
+	body, err = json.Marshal(params)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"marketSymbol":"LTC-BTC","direction":"BUY","type":"CEILING_MARKET","ceiling":"0.1","timeInForce":"IMMEDIATE_OR_CANCEL"}`, string(body))
+
+	params, err = ltc.Buy().Ceiling(d("0.1")).Limit(d("0.003")).FillOrKill().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, CEILING_LIMIT, params.Type)
+
+	for _, invalid := range []OrderBuilder{
+		ltc.Quantity(d("1")),
+		ltc.Buy(),
+		ltc.Sell().Ceiling(d("0.1")),
+		ltc.Buy().Ceiling(d("0.1")).Quantity(d("1")),
+		ltc.Buy().Quantity(d("1")).PostOnly(),
+		NewOrder("").Buy().Quantity(d("1")),
+	} {
+		_, err = invalid.Build()
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	}
+}
@@ -0,0 +1,150 @@
+package bittrex
+
+import (
+	"crypto/tls"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Logger is the logging interface used by the client. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Clock is the time source used for request signing, backoff and rate limiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Option configures a client created with New.
+type Option func(*client)
+
+// WithBaseURL sets the REST API base URL, including the version (default https://api.bittrex.com/v3).
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/") + "/"
+	}
+}
+
+// WithLegacyBaseURL sets the base URL of the v2.0 API still used by GetDistribution
+// (default https://bittrex.com/Api/v2.0).
+func WithLegacyBaseURL(baseURL string) Option {
+	return func(c *client) {
+		c.legacyBaseURL = strings.TrimSuffix(baseURL, "/") + "/"
+	}
+}
+
+// WithWebsocket sets the SignalR host and hub used by websocket subscriptions
+// (default socket-v3.bittrex.com and c3).
+func WithWebsocket(host, hub string) Option {
+	return func(c *client) {
+		c.wsHost = host
+		c.wsHub = hub
+	}
+}
+
+// WithWebsocketTLSConfig sets the TLS configuration of websocket connections, e.g. to
+// trust a test hub: WithWebsocketTLSConfig(hub.TLSConfig()).
+func WithWebsocketTLSConfig(config *tls.Config) Option {
+	return func(c *client) {
+		c.wsTLSConfig = config
+	}
+}
+
+// WithHTTPClient sets the http client used for REST requests.
+// Its Timeout, if any, becomes the request timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+		if httpClient.Timeout > 0 {
+			c.httpTimeout = httpClient.Timeout
+		}
+	}
+}
+
+// WithTimeout sets the timeout of every REST request attempt (default 30s).
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.httpTimeout = timeout
+	}
+}
+
+// WithLogger sets the logger receiving debug dumps and websocket diagnostics.
+func WithLogger(logger Logger) Option {
+	return func(c *client) {
+		c.logger = logger
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry idempotent requests (default DefaultRetryPolicy).
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithRateLimits enables client side throttling with the given request budgets, e.g.
+// DefaultRateLimits. Classes missing from limits are not limited. Without it, requests
+// are not throttled.
+func WithRateLimits(limits map[EndpointClass]RateLimit, policy RateLimitPolicy) Option {
+	return func(c *client) {
+		c.limits = limits
+		c.limitPolicy = policy
+	}
+}
+
+// WithClock sets the time source of the client.
+func WithClock(clock Clock) Option {
+	return func(c *client) {
+		c.clock = clock
+	}
+}
+
+// WithReferenceDataTTL sets how long the reference data cache trusts markets and currencies
+// before reloading them (default DefaultReferenceDataTTL).
+func WithReferenceDataTTL(ttl time.Duration) Option {
+	return func(c *client) {
+		c.refDataTTL = ttl
+	}
+}
+
+// WithDebug enables http request/response dumps.
+func WithDebug(enable bool) Option {
+	return func(c *client) {
+		c.debug = enable
+	}
+}
+
+func newClient(apiKey, apiSecret string, opts ...Option) *client {
+	c := &client{
+		apiKey:        apiKey,
+		apiSecret:     apiSecret,
+		httpClient:    &http.Client{},
+		httpTimeout:   30 * time.Second,
+		baseURL:       API_BASE + API_VERSION + "/",
+		legacyBaseURL: LEGACY_API_BASE,
+		wsHost:        WS_BASE,
+		wsHub:         WS_HUB,
+		logger:        log.New(os.Stderr, "", log.LstdFlags),
+		clock:         systemClock{},
+		retryPolicy:   DefaultRetryPolicy,
+		refDataTTL:    DefaultReferenceDataTTL,
+		limitPolicy:   RATE_LIMIT_BLOCK,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// Built once the options are applied, so the buckets run on the clock of WithClock
+	c.limiter = newRateLimiter(c.limits, c.clock)
+	c.limiter.policy = c.limitPolicy
+	return c
+}
@@ -0,0 +1,48 @@
+package bittrex
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	var gotPath, gotTimestamp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotTimestamp = r.Header.Get("Api-Timestamp")
+		w.Write([]byte(`[{"currencySymbol":"BTC","total":"1.5","available":"1"}]`))
+	}))
+	defer srv.Close()
+
+	clock := &fakeClock{now: time.Unix(1600000000, 0)}
+	bt := New("key", "secret",
+		WithBaseURL(srv.URL+"/v3/"),
+		WithClock(clock),
+		WithRetryPolicy(NoRetry),
+		WithRateLimits(nil, RATE_LIMIT_FAIL_FAST),
+		WithWebsocket("localhost:1234", "hub"),
+	)
+
+	balances, err := bt.GetBalances()
+	assert.Nil(t, err)
+	assert.Len(t, balances, 1)
+	assert.Equal(t, "/v3/balances", gotPath)
+	assert.Equal(t, "1600000000000", gotTimestamp)
+	assert.Equal(t, "localhost:1234", bt.client.wsHost)
+	assert.Equal(t, "hub", bt.client.wsHub)
+}
+
+func TestNewDefaults(t *testing.T) {
+	bt := New("", "")
+	assert.Equal(t, "https://api.bittrex.com/v3/", bt.client.baseURL)
+	assert.Equal(t, WS_BASE, bt.client.wsHost)
+	assert.Equal(t, 30*time.Second, bt.client.httpTimeout)
+	assert.Equal(t, DefaultRetryPolicy, bt.client.retryPolicy)
+
+	bt = NewWithCustomHttpClient("", "", &http.Client{Timeout: 5 * time.Second})
+	assert.Equal(t, 5*time.Second, bt.client.httpTimeout)
+}
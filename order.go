@@ -1,6 +1,7 @@
 package bittrex
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -27,6 +28,8 @@ type Order struct {
 }
 
 // For getorder
+//
+// Deprecated: Order2 is the result of the v1 getorder endpoint. GetOrder returns OrderV3.
 type Order2 struct {
 	AccountId                  string
 	OrderUuid                  string `json:"OrderUuid"`
@@ -53,6 +56,8 @@ type Order2 struct {
 	ConditionTarget            decimal.Decimal
 }
 
+// CreateOrderParams describes an order. Amounts are sent as strings, and left out when zero.
+// NewOrder builds valid params for every order type.
 type CreateOrderParams struct {
 	MarketSymbol string          `json:"marketSymbol"`
 	Direction    OrderDirection  `json:"direction"`
@@ -60,10 +65,31 @@ type CreateOrderParams struct {
 	Quantity     decimal.Decimal `json:"quantity"`
 	TimeInForce  TimeInForce     `json:"timeInForce"`
 
-	Ceiling       float64 `json:"ceiling,omitempty"`
-	Limit         float64 `json:"limit,omitempty"`
-	ClientOrderID string  `json:"clientOrderId,omitempty"`
-	UseAwards     string  `json:"useAwards,omitempty"`
+	Ceiling       decimal.Decimal `json:"ceiling"`
+	Limit         decimal.Decimal `json:"limit"`
+	ClientOrderID string          `json:"clientOrderId,omitempty"`
+	UseAwards     string          `json:"useAwards,omitempty"`
+}
+
+// MarshalJSON leaves out the amounts that are zero, which the order type does not take.
+func (p CreateOrderParams) MarshalJSON() ([]byte, error) {
+	nonZero := func(d decimal.Decimal) *decimal.Decimal {
+		if d.IsZero() {
+			return nil
+		}
+		return &d
+	}
+	return json.Marshal(struct {
+		MarketSymbol  string           `json:"marketSymbol"`
+		Direction     OrderDirection   `json:"direction"`
+		Type          OrderType        `json:"type"`
+		Quantity      *decimal.Decimal `json:"quantity,omitempty"`
+		Ceiling       *decimal.Decimal `json:"ceiling,omitempty"`
+		Limit         *decimal.Decimal `json:"limit,omitempty"`
+		TimeInForce   TimeInForce      `json:"timeInForce"`
+		ClientOrderID string           `json:"clientOrderId,omitempty"`
+		UseAwards     string           `json:"useAwards,omitempty"`
+	}{p.MarketSymbol, p.Direction, p.Type, nonZero(p.Quantity), nonZero(p.Ceiling), nonZero(p.Limit), p.TimeInForce, p.ClientOrderID, p.UseAwards})
 }
 
 type OrderV3 struct {
@@ -96,19 +122,21 @@ type OrderUpdate struct {
 	AccountID string `json:"accountId"`
 	Sequence  int    `json:"sequence"`
 	Delta     struct {
-		ID           string `json:"id"`
-		MarketSymbol string `json:"marketSymbol"`
-		Direction    string `json:"direction"`
-		Type         string `json:"type"`
-		Quantity     string `json:"quantity"`
-		Limit        string `json:"limit"`
-		TimeInForce  string `json:"timeInForce"`
-		FillQuantity string `json:"fillQuantity"`
-		Commission   string `json:"commission"`
-		Proceeds     string `json:"proceeds"`
-		Status       string `json:"status"`
-		CreatedAt    jTime  `json:"createdAt"`
-		UpdatedAt    *jTime `json:"updatedAt"`
-		ClosedAt     *jTime `json:"closedAt"`
+		ID            string `json:"id"`
+		MarketSymbol  string `json:"marketSymbol"`
+		Direction     string `json:"direction"`
+		Type          string `json:"type"`
+		Quantity      string `json:"quantity"`
+		Limit         string `json:"limit"`
+		Ceiling       string `json:"ceiling"`
+		TimeInForce   string `json:"timeInForce"`
+		ClientOrderID string `json:"clientOrderId"`
+		FillQuantity  string `json:"fillQuantity"`
+		Commission    string `json:"commission"`
+		Proceeds      string `json:"proceeds"`
+		Status        string `json:"status"`
+		CreatedAt     jTime  `json:"createdAt"`
+		UpdatedAt     *jTime `json:"updatedAt"`
+		ClosedAt      *jTime `json:"closedAt"`
 	} `json:"delta"`
 }
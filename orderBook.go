@@ -24,8 +24,10 @@ type OrderbV3 struct {
 
 //OrderbookUpdate struct
 type OrderbookUpdate struct {
-	AccountID string     `json:"accountId"`
-	Sequence  int        `json:"sequence"`
-	BidDeltas []OrderbV3 `json:"bidDeltas"`
-	AskDeltas []OrderbV3 `json:"askDeltas"`
+	AccountID    string     `json:"accountId"`
+	MarketSymbol string     `json:"marketSymbol"`
+	Depth        int        `json:"depth"`
+	Sequence     int        `json:"sequence"`
+	BidDeltas    []OrderbV3 `json:"bidDeltas"`
+	AskDeltas    []OrderbV3 `json:"askDeltas"`
 }
@@ -0,0 +1,140 @@
+package bittrex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ERR_ORDER_BOOK_EMPTY     = errors.New("order book side is empty")
+	ERR_NOT_ENOUGH_LIQUIDITY = errors.New("not enough liquidity in the order book")
+)
+
+var bps = decimal.NewFromInt(10000)
+
+// The helpers below expect the sides of the book best first, as returned by
+// GetOrderBook and LocalOrderBook.Snapshot: bids by decreasing rate, asks by increasing rate.
+
+// side returns the levels a taker order of direction fills against: asks for a BUY, bids for a SELL.
+func (o OrderBookV3) side(direction OrderDirection) []OrderbV3 {
+	if direction == SELL {
+		return o.Bid
+	}
+	return o.Ask
+}
+
+// Mid returns the mid price between the best bid and the best ask.
+func (o OrderBookV3) Mid() (decimal.Decimal, error) {
+	if len(o.Bid) == 0 || len(o.Ask) == 0 {
+		return decimal.Zero, ERR_ORDER_BOOK_EMPTY
+	}
+	return o.Bid[0].Rate.Add(o.Ask[0].Rate).Div(decimal.NewFromInt(2)), nil
+}
+
+// VWAP returns the volume weighted average price of a market order of direction for quantity,
+// walking the asks for a BUY and the bids for a SELL. If the book is too thin, it returns the
+// price of the quantity available, which filled reports, along with ERR_NOT_ENOUGH_LIQUIDITY.
+// A quantity that is not positive is rejected with ERR_ORDER_INVALID_PARAMETERS. Levels
+// without a positive rate are skipped.
+func (o OrderBookV3) VWAP(direction OrderDirection, quantity decimal.Decimal) (price, filled decimal.Decimal, err error) {
+	if !quantity.IsPositive() {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("%w: quantity %s must be positive", ERR_ORDER_INVALID_PARAMETERS, quantity)
+	}
+	cost := decimal.Zero
+	for _, level := range o.side(direction) {
+		if !level.Rate.IsPositive() {
+			// A malformed level, it cannot be traded
+			continue
+		}
+		take := decimal.Min(level.Quantity, quantity.Sub(filled))
+		filled = filled.Add(take)
+		cost = cost.Add(take.Mul(level.Rate))
+		if filled.Equal(quantity) {
+			break
+		}
+	}
+	return vwapResult(cost, filled, filled.LessThan(quantity))
+}
+
+// VWAPQuote is VWAP for an order spending (BUY) or receiving (SELL) amount of the quote currency,
+// as a CEILING_MARKET order does. filled is the quantity of the base currency exchanged.
+func (o OrderBookV3) VWAPQuote(direction OrderDirection, amount decimal.Decimal) (price, filled decimal.Decimal, err error) {
+	if !amount.IsPositive() {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("%w: amount %s must be positive", ERR_ORDER_INVALID_PARAMETERS, amount)
+	}
+	cost := decimal.Zero
+	for _, level := range o.side(direction) {
+		if !level.Rate.IsPositive() {
+			// A malformed level, it cannot be traded
+			continue
+		}
+		left := amount.Sub(cost)
+		if levelCost := level.Quantity.Mul(level.Rate); levelCost.LessThan(left) {
+			filled = filled.Add(level.Quantity)
+			cost = cost.Add(levelCost)
+			continue
+		}
+		filled = filled.Add(left.Div(level.Rate))
+		cost = amount
+		break
+	}
+	return vwapResult(cost, filled, cost.LessThan(amount))
+}
+
+func vwapResult(cost, filled decimal.Decimal, short bool) (price, _ decimal.Decimal, err error) {
+	if filled.IsZero() {
+		return decimal.Zero, filled, ERR_ORDER_BOOK_EMPTY
+	}
+	if short {
+		err = ERR_NOT_ENOUGH_LIQUIDITY
+	}
+	return cost.Div(filled), filled, err
+}
+
+// Slippage returns the expected slippage of a market order of direction for quantity, in basis
+// points of the mid price. It is positive when the order fills worse than mid: above it for a
+// BUY, below it for a SELL. err is ERR_NOT_ENOUGH_LIQUIDITY if the book cannot fill quantity.
+func (o OrderBookV3) Slippage(direction OrderDirection, quantity decimal.Decimal) (decimal.Decimal, error) {
+	mid, err := o.Mid()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	price, _, err := o.VWAP(direction, quantity)
+	if err != nil && err != ERR_NOT_ENOUGH_LIQUIDITY {
+		return decimal.Zero, err
+	}
+	slippage := price.Sub(mid).Mul(bps).Div(mid)
+	if direction == SELL {
+		slippage = slippage.Neg()
+	}
+	return slippage, err
+}
+
+// DepthWithin returns the cumulative quantity of the asks (BUY) or the bids (SELL) priced within
+// basisPoints of the mid price.
+func (o OrderBookV3) DepthWithin(direction OrderDirection, basisPoints decimal.Decimal) (decimal.Decimal, error) {
+	mid, err := o.Mid()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	offset := mid.Mul(basisPoints).Div(bps)
+	if direction == SELL {
+		return o.MaxQuantityAtPrice(SELL, mid.Sub(offset)), nil
+	}
+	return o.MaxQuantityAtPrice(BUY, mid.Add(offset)), nil
+}
+
+// MaxQuantityAtPrice returns the quantity a limit order of direction can take from the book
+// without trading beyond priceCap: the asks at or below it for a BUY, the bids at or above it for a SELL.
+func (o OrderBookV3) MaxQuantityAtPrice(direction OrderDirection, priceCap decimal.Decimal) decimal.Decimal {
+	quantity := decimal.Zero
+	for _, level := range o.side(direction) {
+		if direction == SELL && level.Rate.LessThan(priceCap) || direction != SELL && level.Rate.GreaterThan(priceCap) {
+			break
+		}
+		quantity = quantity.Add(level.Quantity)
+	}
+	return quantity
+}
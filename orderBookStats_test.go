@@ -0,0 +1,109 @@
+package bittrex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBook = OrderBookV3{
+	Bid: []OrderbV3{
+		{Rate: d("99"), Quantity: d("1")},
+		{Rate: d("98"), Quantity: d("2")},
+		{Rate: d("95"), Quantity: d("10")},
+	},
+	Ask: []OrderbV3{
+		{Rate: d("101"), Quantity: d("1")},
+		{Rate: d("102"), Quantity: d("3")},
+		{Rate: d("110"), Quantity: d("10")},
+	},
+}
+
+func TestOrderBookVWAP(t *testing.T) {
+	price, filled, err := testBook.VWAP(BUY, d("3"))
+	assert.Nil(t, err)
+	assert.True(t, d("3").Equal(filled))
+	assert.Equal(t, "101.6666666666666667", price.String())
+
+	price, filled, err = testBook.VWAP(SELL, d("2"))
+	assert.Nil(t, err)
+	assert.True(t, d("98.5").Equal(price))
+
+	price, filled, err = testBook.VWAP(SELL, d("20"))
+	assert.Equal(t, ERR_NOT_ENOUGH_LIQUIDITY, err)
+	assert.True(t, d("13").Equal(filled))
+	assert.True(t, d("1245").Div(d("13")).Equal(price))
+
+	_, _, err = OrderBookV3{}.VWAP(BUY, d("1"))
+	assert.Equal(t, ERR_ORDER_BOOK_EMPTY, err)
+}
+
+func TestOrderBookVWAPQuote(t *testing.T) {
+	// 101 for 1, then 204 for 2
+	price, filled, err := testBook.VWAPQuote(BUY, d("305"))
+	assert.Nil(t, err)
+	assert.True(t, d("3").Equal(filled))
+	assert.True(t, d("305").Div(d("3")).Equal(price))
+
+	_, filled, err = testBook.VWAPQuote(SELL, d("99"))
+	assert.Nil(t, err)
+	assert.True(t, d("1").Equal(filled))
+
+	_, _, err = testBook.VWAPQuote(BUY, d("100000"))
+	assert.Equal(t, ERR_NOT_ENOUGH_LIQUIDITY, err)
+}
+
+func TestOrderBookVWAPInvalidAmount(t *testing.T) {
+	for _, amount := range []string{"0", "-1"} {
+		_, _, err := testBook.VWAP(BUY, d(amount))
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS), amount)
+		_, _, err = testBook.VWAPQuote(SELL, d(amount))
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS), amount)
+		_, err = testBook.Slippage(BUY, d(amount))
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS), amount)
+	}
+}
+
+func TestOrderBookVWAPZeroRate(t *testing.T) {
+	book := OrderBookV3{Ask: []OrderbV3{
+		{Rate: decimal.Zero, Quantity: d("5")},
+		{Rate: d("101"), Quantity: d("1")},
+	}}
+	price, filled, err := book.VWAPQuote(BUY, d("101"))
+	assert.Nil(t, err)
+	assert.True(t, d("101").Equal(price))
+	assert.True(t, d("1").Equal(filled))
+	price, _, err = book.VWAP(BUY, d("1"))
+	assert.Nil(t, err)
+	assert.True(t, d("101").Equal(price))
+}
+
+func TestOrderBookSlippage(t *testing.T) {
+	mid, err := testBook.Mid()
+	assert.Nil(t, err)
+	assert.True(t, d("100").Equal(mid))
+
+	slippage, err := testBook.Slippage(BUY, d("1"))
+	assert.Nil(t, err)
+	assert.True(t, d("100").Equal(slippage))
+
+	slippage, err = testBook.Slippage(SELL, d("3"))
+	assert.Nil(t, err)
+	assert.True(t, d("166.6667").Equal(slippage.Round(4)))
+}
+
+func TestOrderBookDepth(t *testing.T) {
+	depth, err := testBook.DepthWithin(BUY, d("200"))
+	assert.Nil(t, err)
+	assert.True(t, d("4").Equal(depth))
+
+	depth, err = testBook.DepthWithin(SELL, d("500"))
+	assert.Nil(t, err)
+	assert.True(t, d("13").Equal(depth))
+
+	assert.True(t, d("1").Equal(testBook.MaxQuantityAtPrice(BUY, d("101.5"))))
+	assert.True(t, d("3").Equal(testBook.MaxQuantityAtPrice(SELL, d("98"))))
+	assert.True(t, testBook.MaxQuantityAtPrice(SELL, d("100")).IsZero())
+}
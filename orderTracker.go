@@ -0,0 +1,545 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Statuses of an order
+const (
+	ORDER_OPEN   = "OPEN"
+	ORDER_CLOSED = "CLOSED"
+)
+
+// OrderEventType is the kind of change an OrderTracker reports.
+type OrderEventType string
+
+// Order events. A closing order reports ORDER_EVENT_FILL or ORDER_EVENT_CANCEL, then
+// ORDER_EVENT_CLOSE; a cancelled order that filled in part since the last change reports
+// ORDER_EVENT_PARTIAL_FILL first.
+const (
+	ORDER_EVENT_PARTIAL_FILL OrderEventType = "PARTIAL_FILL"
+	ORDER_EVENT_FILL         OrderEventType = "FILL"
+	ORDER_EVENT_CANCEL       OrderEventType = "CANCEL"
+	ORDER_EVENT_CLOSE        OrderEventType = "CLOSE"
+)
+
+// OrderEvent is a change of an order seen by an OrderTracker.
+type OrderEvent struct {
+	Type OrderEventType
+	// Order is the state of the order after the change.
+	Order OrderV3
+	// Previous is the state of the order before the change, zero for an order not tracked yet.
+	Previous OrderV3
+	// Filled is the quantity filled by the change.
+	Filled decimal.Decimal
+}
+
+// OrderTracker keeps the state of the account orders up to date from the websocket.
+//
+// It loads the open orders, applies the order deltas in sequence order and reconciles with
+// REST whenever a delta is missing or malformed, or the stream reports a gap: orders it holds
+// open that are no longer open are fetched one by one, those the account no longer knows
+// closing as last seen, and the orders closed since the previous load are walked for those
+// opened and closed meanwhile. Closed orders are kept until Forget. Its methods are safe for concurrent use.
+type OrderTracker struct {
+	b      *Bittrex
+	stream *Stream
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	resync chan struct{}
+
+	mu          sync.RWMutex
+	orders      map[string]OrderV3
+	sequence    int
+	synced      bool
+	seeded      bool          // the first load reports no event
+	loadedAt    time.Time     // start of the last load, orders closed since may be unknown
+	pending     []OrderUpdate // deltas received while reconciling
+	waiters     chan struct{} // closed and replaced whenever the tracker gets synced
+	subscribers map[int]orderSubscriber
+	nextID      int
+	queue       []OrderEvent // events waiting for delivery
+	delivering  bool
+}
+
+type orderSubscriber struct {
+	event OrderEventType
+	f     func(OrderEvent)
+}
+
+// TrackOrders tracks the orders of the account from the order channel of stream, replacing
+// any handler of the channel. It returns once the open orders are loaded, or ctx.Err() if ctx
+// is done first.
+func (b *Bittrex) TrackOrders(ctx context.Context, stream *Stream) (*OrderTracker, error) {
+	t := &OrderTracker{
+		b:           b,
+		stream:      stream,
+		resync:      make(chan struct{}, 1),
+		orders:      make(map[string]OrderV3),
+		waiters:     make(chan struct{}),
+		subscribers: make(map[int]orderSubscriber),
+	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+
+	if err := stream.Subscribe(ctx, ORDER, t.handle); err != nil {
+		t.cancel()
+		return nil, err
+	}
+	go t.run()
+	t.requestResync()
+
+	if err := t.WaitSynced(ctx); err != nil {
+		t.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Close stops tracking the orders and unsubscribes from the order channel.
+func (t *OrderTracker) Close() error {
+	t.cancel()
+	return t.stream.Unsubscribe(context.Background(), ORDER)
+}
+
+// OnFill calls f when an order is entirely filled. It returns a function removing f.
+func (t *OrderTracker) OnFill(f func(OrderEvent)) (unsubscribe func()) {
+	return t.subscribe(ORDER_EVENT_FILL, f)
+}
+
+// OnPartialFill calls f when an order fills in part. It returns a function removing f.
+func (t *OrderTracker) OnPartialFill(f func(OrderEvent)) (unsubscribe func()) {
+	return t.subscribe(ORDER_EVENT_PARTIAL_FILL, f)
+}
+
+// OnCancel calls f when an order closes before it is entirely filled. It returns a function
+// removing f.
+func (t *OrderTracker) OnCancel(f func(OrderEvent)) (unsubscribe func()) {
+	return t.subscribe(ORDER_EVENT_CANCEL, f)
+}
+
+// OnClose calls f when an order closes, filled or not. It returns a function removing f.
+func (t *OrderTracker) OnClose(f func(OrderEvent)) (unsubscribe func()) {
+	return t.subscribe(ORDER_EVENT_CLOSE, f)
+}
+
+// subscribe registers f for event. Callbacks run one at a time in the order of the changes,
+// from the stream goroutine or the goroutine reconciling, and may call the methods of the tracker.
+func (t *OrderTracker) subscribe(event OrderEventType, f func(OrderEvent)) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := t.nextID
+	t.nextID++
+	t.subscribers[id] = orderSubscriber{event: event, f: f}
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.subscribers, id)
+	}
+}
+
+// WaitSynced blocks until the tracker is synchronised, or returns ctx.Err() once ctx is done.
+func (t *OrderTracker) WaitSynced(ctx context.Context) error {
+	for {
+		t.mu.RLock()
+		synced, waiters := t.synced, t.waiters
+		t.mu.RUnlock()
+		if synced {
+			return nil
+		}
+		select {
+		case <-waiters:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Synced reports whether the tracker reflects the account. It is false while reconciling.
+func (t *OrderTracker) Synced() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.synced
+}
+
+// Sequence returns the sequence number of the last delta applied.
+func (t *OrderTracker) Sequence() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.sequence
+}
+
+// Order returns the state of a tracked order. ok is false if the order is not tracked.
+func (t *OrderTracker) Order(id string) (order OrderV3, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	order, ok = t.orders[id]
+	return
+}
+
+// Orders returns every tracked order, open or closed, oldest first.
+func (t *OrderTracker) Orders() []OrderV3 {
+	return t.filter(func(OrderV3) bool { return true })
+}
+
+// OpenOrders returns the open orders, oldest first.
+func (t *OrderTracker) OpenOrders() []OrderV3 {
+	return t.filter(func(o OrderV3) bool { return o.Status != ORDER_CLOSED })
+}
+
+func (t *OrderTracker) filter(keep func(OrderV3) bool) []OrderV3 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	orders := []OrderV3{}
+	for _, o := range t.orders {
+		if keep(o) {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+// Forget stops tracking a closed order. Open orders stay tracked.
+func (t *OrderTracker) Forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if o, ok := t.orders[id]; ok && o.Status == ORDER_CLOSED {
+		delete(t.orders, id)
+	}
+}
+
+// handle receives the messages of the order channel.
+func (t *OrderTracker) handle(method string, payload json.RawMessage) {
+	if method == STREAM_GAP {
+		t.mu.Lock()
+		t.invalidate()
+		t.mu.Unlock()
+		t.requestResync()
+		return
+	}
+
+	var u OrderUpdate
+	if err := json.Unmarshal(payload, &u); err != nil {
+		t.b.client.logger.Printf("order Unmarshal err: %s", err.Error())
+		return
+	}
+
+	t.mu.Lock()
+	var events []OrderEvent
+	switch {
+	case !t.synced:
+		t.pending = append(t.pending, u)
+	case u.Sequence <= t.sequence:
+		// Already part of the open orders loaded
+	case u.Sequence == t.sequence+1:
+		var err error
+		if events, err = t.apply(u); err != nil {
+			t.reject(u, err)
+		}
+	default:
+		t.b.client.logger.Printf("order: sequence gap %d -> %d, reconciling", t.sequence, u.Sequence)
+		t.invalidate()
+		t.pending = append(t.pending, u)
+		t.requestResync()
+	}
+	t.deliver(events)
+}
+
+// reject drops a delta that cannot be applied and reconciles. t.mu must be held.
+func (t *OrderTracker) reject(u OrderUpdate, err error) {
+	t.b.client.logger.Printf("order %s: delta %d: %s, reconciling", u.Delta.ID, u.Sequence, err)
+	t.invalidate()
+	t.requestResync()
+}
+
+// invalidate marks the tracker out of sync. t.mu must be held.
+func (t *OrderTracker) invalidate() {
+	t.synced = false
+	t.pending = nil
+}
+
+func (t *OrderTracker) requestResync() {
+	select {
+	case t.resync <- struct{}{}:
+	default:
+	}
+}
+
+// run reconciles whenever it is requested, until Close.
+func (t *OrderTracker) run() {
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-t.resync:
+		}
+
+		for attempt := 1; !t.load(); attempt++ {
+			if sleepCtx(t.ctx, t.b.client.clock, resyncBackoff.backoff(attempt)) != nil {
+				return
+			}
+		}
+	}
+}
+
+// load fetches the open orders and the orders that closed since the previous load, then
+// replays the pending deltas following them. It returns false if it must be retried.
+func (t *OrderTracker) load() bool {
+	started := t.b.client.clock.Now()
+	open, sequence, err := t.b.GetOpenOrdersWithSequenceCtx(t.ctx)
+	if err != nil {
+		if t.ctx.Err() == nil {
+			t.b.client.logger.Printf("order: open orders error: %s", err)
+		}
+		return false
+	}
+
+	states := make(map[string]OrderV3, len(open))
+	for _, o := range open {
+		states[o.ID] = o
+	}
+	t.mu.RLock()
+	since := t.loadedAt
+	var closed []OrderV3
+	for id, o := range t.orders {
+		if _, ok := states[id]; !ok && o.Status != ORDER_CLOSED {
+			closed = append(closed, o)
+		}
+	}
+	t.mu.RUnlock()
+	for _, previous := range closed {
+		o, err := t.b.GetOrderCtx(t.ctx, previous.ID)
+		if IsNotFound(err) {
+			// Gone from the account: it closed as last seen
+			o, err = previous, nil
+			o.Status = ORDER_CLOSED
+			o.ClosedAt = t.b.client.clock.Now()
+		}
+		if err != nil {
+			if t.ctx.Err() == nil {
+				t.b.client.logger.Printf("order %s: error: %s", previous.ID, err)
+			}
+			return false
+		}
+		states[o.ID] = o
+	}
+	// Orders opened and closed while out of sync are neither open nor tracked
+	if !since.IsZero() {
+		it := t.b.ClosedOrdersIter(t.ctx, ClosedOrdersParams{PageParams: PageParams{PageSize: 200, StartDate: since}})
+		for it.Next() {
+			if o := it.Order(); !o.ClosedAt.Before(since) {
+				states[o.ID] = o
+			}
+		}
+		if err := it.Err(); err != nil {
+			if t.ctx.Err() == nil {
+				t.b.client.logger.Printf("order: closed orders error: %s", err)
+			}
+			return false
+		}
+	}
+
+	t.mu.Lock()
+	if t.synced {
+		t.mu.Unlock()
+		return true
+	}
+
+	sort.SliceStable(t.pending, func(i, j int) bool { return t.pending[i].Sequence < t.pending[j].Sequence })
+	next := sequence + 1
+	for _, u := range t.pending {
+		if u.Sequence < next {
+			continue
+		}
+		if u.Sequence > next {
+			// The orders are older than the deltas received, or a delta is missing:
+			// a later load will cover it
+			t.mu.Unlock()
+			return false
+		}
+		next++
+	}
+
+	ids := make([]string, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var events []OrderEvent
+	for _, id := range ids {
+		if t.seeded {
+			events = append(events, t.update(states[id])...)
+		} else {
+			t.orders[id] = states[id]
+		}
+	}
+	t.sequence = sequence
+	t.loadedAt = started
+	t.seeded = true
+	t.synced = true
+	pending := t.pending
+	t.pending = nil
+	for _, u := range pending {
+		if u.Sequence != t.sequence+1 {
+			continue
+		}
+		applied, err := t.apply(u)
+		if err != nil {
+			t.reject(u, err)
+			break
+		}
+		events = append(events, applied...)
+	}
+	if t.synced {
+		close(t.waiters)
+		t.waiters = make(chan struct{})
+	}
+	t.deliver(events)
+	return true
+}
+
+// apply applies a delta, or returns the error of parsing it. t.mu must be held.
+func (t *OrderTracker) apply(u OrderUpdate) ([]OrderEvent, error) {
+	o, err := orderFromDelta(u, t.orders[u.Delta.ID])
+	if err != nil {
+		return nil, err
+	}
+	t.sequence = u.Sequence
+	return t.update(o), nil
+}
+
+// update records the state of an order and returns the events of the change. A state
+// older than the one recorded, closed or more filled, is ignored. t.mu must be held.
+func (t *OrderTracker) update(o OrderV3) []OrderEvent {
+	previous, known := t.orders[o.ID]
+	if known && (previous.Status == ORDER_CLOSED || o.FillQuantity.LessThan(previous.FillQuantity)) {
+		return nil
+	}
+	t.orders[o.ID] = o
+
+	event := OrderEvent{Order: o, Previous: previous, Filled: o.FillQuantity.Sub(previous.FillQuantity)}
+	var events []OrderEvent
+	add := func(typ OrderEventType) {
+		event.Type = typ
+		events = append(events, event)
+	}
+	if o.Status != ORDER_CLOSED {
+		if event.Filled.IsPositive() {
+			add(ORDER_EVENT_PARTIAL_FILL)
+		}
+		return events
+	}
+	if filled(o) {
+		add(ORDER_EVENT_FILL)
+	} else {
+		if event.Filled.IsPositive() {
+			add(ORDER_EVENT_PARTIAL_FILL)
+		}
+		add(ORDER_EVENT_CANCEL)
+	}
+	add(ORDER_EVENT_CLOSE)
+	return events
+}
+
+// filled reports whether a closed order filled entirely. Ceiling orders have no quantity:
+// they fill until their ceiling is spent, or as much as the market allows.
+func filled(o OrderV3) bool {
+	if o.Quantity.IsZero() {
+		return o.FillQuantity.IsPositive()
+	}
+	return o.FillQuantity.GreaterThanOrEqual(o.Quantity)
+}
+
+// deliver queues events, releases t.mu, then calls the subscribers of the events queued
+// unless another goroutine is already doing so: events are delivered in order, one at a time.
+func (t *OrderTracker) deliver(events []OrderEvent) {
+	t.queue = append(t.queue, events...)
+	if t.delivering {
+		t.mu.Unlock()
+		return
+	}
+	t.delivering = true
+	for len(t.queue) > 0 {
+		queue := t.queue
+		t.queue = nil
+		subscribers := make([]orderSubscriber, 0, len(t.subscribers))
+		for id := 0; id < t.nextID; id++ {
+			if s, ok := t.subscribers[id]; ok {
+				subscribers = append(subscribers, s)
+			}
+		}
+		t.mu.Unlock()
+		for _, e := range queue {
+			for _, s := range subscribers {
+				if s.event == e.Type {
+					s.f(e)
+				}
+			}
+		}
+		t.mu.Lock()
+	}
+	t.delivering = false
+	t.mu.Unlock()
+}
+
+// orderFromDelta converts the delta of u to an order, keeping from previous the fields the
+// delta lacks. It fails on a malformed amount.
+func orderFromDelta(u OrderUpdate, previous OrderV3) (OrderV3, error) {
+	var err error
+	parse := func(name, s string) decimal.Decimal {
+		amount, parseErr := parseAmount(s)
+		if parseErr != nil && err == nil {
+			err = fmt.Errorf("%s: %w", name, parseErr)
+		}
+		return amount
+	}
+
+	d := u.Delta
+	o := previous
+	o.ID = d.ID
+	o.MarketSymbol = d.MarketSymbol
+	o.Direction = d.Direction
+	o.Type = d.Type
+	o.Quantity = parse("quantity", d.Quantity)
+	o.Limit = parse("limit", d.Limit)
+	if d.Ceiling != "" {
+		o.Ceiling = parse("ceiling", d.Ceiling)
+	}
+	o.TimeInForce = d.TimeInForce
+	if d.ClientOrderID != "" {
+		o.ClientOrderID = d.ClientOrderID
+	}
+	o.FillQuantity = parse("fillQuantity", d.FillQuantity)
+	o.Commission = parse("commission", d.Commission)
+	o.Proceeds = parse("proceeds", d.Proceeds)
+	o.Status = d.Status
+	o.CreatedAt = d.CreatedAt.Time
+	if d.UpdatedAt != nil {
+		o.UpdatedAt = d.UpdatedAt.Time
+	}
+	if d.ClosedAt != nil {
+		o.ClosedAt = d.ClosedAt.Time
+	}
+	return o, err
+}
+
+// parseAmount parses an amount of a websocket delta, zero if it is missing.
+func parseAmount(s string) (decimal.Decimal, error) {
+	if s == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(s)
+}
@@ -0,0 +1,227 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func newTestOrderTracker(t *testing.T) (*bittrextest.Server, *bittrextest.Hub, *Bittrex, *OrderTracker, chan OrderEvent) {
+	srv := bittrextest.NewServer("key", "secret")
+	t.Cleanup(srv.Close)
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	srv.SetBalance("BTC", d("1"))
+
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()), WithRetryPolicy(NoRetry))
+	stream, err := b.OpenStream(context.Background(), WithReconnect(RetryPolicy{InitialBackoff: 10 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stream.Close() })
+
+	// An order open before tracking starts
+	placeOrder(t, b, "2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tracker, err := b.TrackOrders(ctx, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tracker.Close() })
+
+	events := make(chan OrderEvent, 16)
+	send := func(e OrderEvent) { events <- e }
+	tracker.OnPartialFill(send)
+	tracker.OnFill(send)
+	tracker.OnCancel(send)
+	tracker.OnClose(send)
+	return srv, hub, b, tracker, events
+}
+
+func placeOrder(t *testing.T, b *Bittrex, quantity string) OrderV3 {
+	params, err := NewOrder("LTC-BTC").Buy().Quantity(d(quantity)).Limit(d("0.004")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := b.CreateOrder(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// pushOrder pushes the current state of an order as the delta of sequence.
+func pushOrder(t *testing.T, srv *bittrextest.Server, hub *bittrextest.Hub, id string, sequence int64) {
+	o, _ := srv.Order(id)
+	if err := hub.PushOrder(bittrextest.OrderUpdate{AccountID: "account", Sequence: sequence, Delta: o}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nextEvent(t *testing.T, events chan OrderEvent) OrderEvent {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order event")
+		return OrderEvent{}
+	}
+}
+
+func TestOrderTrackerDeltas(t *testing.T) {
+	srv, hub, _, tracker, events := newTestOrderTracker(t)
+	assert.Equal(t, int(srv.OrderSequence()), tracker.Sequence())
+	open := tracker.OpenOrders()
+	if !assert.Len(t, open, 1) {
+		return
+	}
+	id := open[0].ID
+
+	// A partial fill
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("0.5"))
+	pushOrder(t, srv, hub, id, srv.OrderSequence())
+	e := nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_PARTIAL_FILL, e.Type)
+	assert.True(t, d("0.5").Equal(e.Filled))
+	assert.True(t, e.Previous.FillQuantity.IsZero())
+	o, ok := tracker.Order(id)
+	assert.True(t, ok)
+	assert.True(t, d("0.5").Equal(o.FillQuantity))
+	assert.Equal(t, ORDER_OPEN, o.Status)
+
+	// The rest fills and the order closes: the last fill is replayed
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("1.5"))
+	pushOrder(t, srv, hub, id, srv.OrderSequence()-1)
+	pushOrder(t, srv, hub, id, srv.OrderSequence())
+	e = nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_FILL, e.Type)
+	assert.True(t, d("1.5").Equal(e.Filled))
+	assert.Equal(t, ORDER_EVENT_CLOSE, nextEvent(t, events).Type)
+	assert.Empty(t, tracker.OpenOrders())
+	assert.Len(t, tracker.Orders(), 1)
+	assert.Equal(t, int(srv.OrderSequence()), tracker.Sequence())
+
+	tracker.Forget(id)
+	assert.Empty(t, tracker.Orders())
+}
+
+func TestOrderTrackerReconnect(t *testing.T) {
+	_, hub, b, tracker, events := newTestOrderTracker(t)
+	id := tracker.OpenOrders()[0].ID
+
+	// Cancelled while the stream is down
+	_, err := b.CancelOrder(id)
+	assert.Nil(t, err)
+	hub.Disconnect()
+
+	e := nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_CANCEL, e.Type)
+	assert.Equal(t, id, e.Order.ID)
+	assert.Equal(t, ORDER_EVENT_CLOSE, nextEvent(t, events).Type)
+	o, _ := tracker.Order(id)
+	assert.Equal(t, ORDER_CLOSED, o.Status)
+	assert.False(t, o.ClosedAt.IsZero())
+}
+
+func TestOrderTrackerSequenceGap(t *testing.T) {
+	srv, hub, b, tracker, events := newTestOrderTracker(t)
+
+	// The delta creating the order is missing
+	order := placeOrder(t, b, "1")
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("2.25"))
+	pushOrder(t, srv, hub, order.ID, srv.OrderSequence())
+
+	// The first order fills, the new one in part
+	var types []OrderEventType
+	for i := 0; i < 3; i++ {
+		e := nextEvent(t, events)
+		types = append(types, e.Type)
+		if e.Order.ID == order.ID {
+			assert.True(t, d("0.25").Equal(e.Filled))
+		}
+	}
+	assert.ElementsMatch(t, []OrderEventType{ORDER_EVENT_FILL, ORDER_EVENT_CLOSE, ORDER_EVENT_PARTIAL_FILL}, types)
+	assert.True(t, tracker.Synced())
+	assert.Equal(t, int(srv.OrderSequence()), tracker.Sequence())
+	assert.Len(t, tracker.OpenOrders(), 1)
+}
+
+func TestOrderTrackerReconnectClosedMeanwhile(t *testing.T) {
+	srv, hub, b, tracker, events := newTestOrderTracker(t)
+
+	// Placed and filled while the stream is down: no delta and not open either
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.005"), d("1"))
+	params, err := NewOrder("LTC-BTC").Buy().Quantity(d("1")).Build()
+	assert.Nil(t, err)
+	order, err := b.CreateOrder(params)
+	assert.Nil(t, err)
+	assert.Equal(t, ORDER_CLOSED, order.Status)
+	hub.Disconnect()
+
+	e := nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_FILL, e.Type)
+	assert.Equal(t, order.ID, e.Order.ID)
+	assert.True(t, d("1").Equal(e.Filled))
+	assert.Equal(t, ORDER_EVENT_CLOSE, nextEvent(t, events).Type)
+	o, ok := tracker.Order(order.ID)
+	assert.True(t, ok)
+	assert.Equal(t, ORDER_CLOSED, o.Status)
+	assert.Len(t, tracker.OpenOrders(), 1)
+}
+
+func TestOrderTrackerMalformedDelta(t *testing.T) {
+	srv, hub, _, tracker, events := newTestOrderTracker(t)
+	id := tracker.OpenOrders()[0].ID
+
+	// A delta closing the order with a malformed fill is dropped, not read as a cancel
+	o, _ := srv.Order(id)
+	data, err := json.Marshal(o)
+	assert.Nil(t, err)
+	var delta map[string]interface{}
+	assert.Nil(t, json.Unmarshal(data, &delta))
+	delta["fillQuantity"] = "2x"
+	delta["status"] = ORDER_CLOSED
+	assert.Nil(t, hub.Push(ORDER, bittrextest.MethodOrder, map[string]interface{}{
+		"accountId": "account",
+		"sequence":  srv.OrderSequence() + 1,
+		"delta":     delta,
+	}))
+
+	// The next change is the first event
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("0.5"))
+	pushOrder(t, srv, hub, id, srv.OrderSequence())
+	e := nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_PARTIAL_FILL, e.Type)
+	assert.True(t, d("0.5").Equal(e.Filled))
+	o2, _ := tracker.Order(id)
+	assert.Equal(t, ORDER_OPEN, o2.Status)
+	assert.True(t, d("0.5").Equal(o2.FillQuantity))
+}
+
+func TestOrderTrackerReconnectOrderNotFound(t *testing.T) {
+	_, hub, _, tracker, events := newTestOrderTracker(t)
+
+	// A tracked order the account no longer knows closes instead of staying open
+	tracker.mu.Lock()
+	tracker.orders["gone"] = OrderV3{ID: "gone", MarketSymbol: "LTC-BTC", Quantity: d("1"), Status: ORDER_OPEN}
+	tracker.mu.Unlock()
+	hub.Disconnect()
+
+	e := nextEvent(t, events)
+	assert.Equal(t, ORDER_EVENT_CANCEL, e.Type)
+	assert.Equal(t, "gone", e.Order.ID)
+	assert.Equal(t, ORDER_EVENT_CLOSE, nextEvent(t, events).Type)
+	o, _ := tracker.Order("gone")
+	assert.Equal(t, ORDER_CLOSED, o.Status)
+	assert.False(t, o.ClosedAt.IsZero())
+	assert.Len(t, tracker.OpenOrders(), 1)
+}
@@ -0,0 +1,144 @@
+package bittrex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func newTestServer(t *testing.T) (*bittrextest.Server, *Bittrex) {
+	srv := bittrextest.NewServer("key", "secret")
+	t.Cleanup(srv.Close)
+	srv.AddMarket(bittrextest.Market{
+		Symbol:              "LTC-BTC",
+		BaseCurrencySymbol:  "LTC",
+		QuoteCurrencySymbol: "BTC",
+		MinTradeSize:        d("0.01"),
+		Precision:           8,
+	})
+	srv.SetCommissionRate(decimal.Zero)
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry))
+	return srv, b
+}
+
+func TestGetOrder(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.0041"), d("1"))
+
+	created, err := b.CreateOrder(CreateOrderParams{
+		MarketSymbol:  "LTC-BTC",
+		Direction:     BUY,
+		Type:          LIMIT,
+		Quantity:      d("1.5"),
+		Limit:         d("0.005"),
+		TimeInForce:   IMMEDIATE_OR_CANCEL,
+		ClientOrderID: "client-id",
+	})
+	assert.Nil(t, err)
+
+	order, err := b.GetOrder(created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "CLOSED", order.Status)
+	assert.True(t, d("1.5").Equal(order.FillQuantity))
+
+	byClientID, err := b.GetOrderByClientOrderID("client-id")
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, byClientID.ID)
+	_, err = b.GetOrderByClientOrderID("unknown")
+	assert.True(t, IsNotFound(err))
+
+	executions, err := b.GetOrderExecutions(created.ID)
+	assert.Nil(t, err)
+	if assert.Len(t, executions, 2) {
+		assert.True(t, d("0.004").Equal(executions[0].Rate))
+		assert.True(t, d("1").Equal(executions[0].Quantity))
+		assert.True(t, d("0.5").Equal(executions[1].Quantity))
+		assert.True(t, executions[1].IsTaker)
+		assert.Equal(t, created.ID, executions[1].OrderID)
+	}
+
+	_, err = b.GetOrder("unknown")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestGetOrderByClientOrderIDHistory(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+
+	// Immediate orders close at once, the first one deep in the history
+	place := func(clientOrderID string) OrderV3 {
+		order, err := b.CreateOrder(CreateOrderParams{
+			MarketSymbol:  "LTC-BTC",
+			Direction:     BUY,
+			Type:          LIMIT,
+			Quantity:      d("1"),
+			Limit:         d("0.004"),
+			TimeInForce:   IMMEDIATE_OR_CANCEL,
+			ClientOrderID: clientOrderID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+	first := place("first")
+	for i := 0; i < 250; i++ {
+		place("")
+	}
+
+	order, err := b.GetOrderByClientOrderID("first")
+	assert.Nil(t, err)
+	assert.Equal(t, first.ID, order.ID)
+	_, err = b.GetOrderByClientOrderID("")
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+}
+
+func TestOrderHelpers(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("10"))
+
+	order, err := b.MarketBuy("LTC-BTC", d("2"))
+	assert.Nil(t, err)
+	assert.Equal(t, "CLOSED", order.Status)
+	assert.True(t, d("2").Equal(order.FillQuantity))
+
+	order, err = b.CeilingMarketBuy("LTC-BTC", d("0.004"))
+	assert.Nil(t, err)
+	assert.True(t, d("1").Equal(order.FillQuantity))
+
+	order, err = b.LimitSell("LTC-BTC", d("3"), d("0.005"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	assert.Equal(t, "OPEN", order.Status)
+	assert.Equal(t, string(SELL), order.Direction)
+
+	_, err = b.PostOnlyLimit("LTC-BTC", BUY, d("1"), d("0.0045"))
+	assert.Error(t, err)
+	order, err = b.PostOnlyLimit("LTC-BTC", BUY, d("1"), d("0.0035"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(POST_ONLY_GOOD_TIL_CANCELLED), order.TimeInForce)
+
+	id, err := b.BuyLimit("LTC-BTC", d("1"), d("0.003"))
+	assert.Nil(t, err)
+	order, err = b.GetOrder(id)
+	assert.Nil(t, err)
+	assert.Equal(t, string(LIMIT), order.Type)
+
+	// Invalid orders are rejected before reaching the server
+	requests := len(srv.Requests())
+	_, err = b.LimitBuy("LTC-BTC", d("1"), decimal.Zero, GOOD_TIL_CANCELLED)
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	_, err = b.LimitBuy("LTC-BTC", d("1"), d("0.003"), BUY_NOW)
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	_, err = b.MarketSell("", d("1"))
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	_, err = b.MarketSell("LTC-BTC", d("-1"))
+	assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	assert.Len(t, srv.Requests(), requests)
+}
@@ -0,0 +1,221 @@
+package bittrex
+
+import (
+	"context"
+	"time"
+)
+
+// PageParams are the pagination and date filters of the v3 history endpoints, which list
+// items from the most recent. A page holds PageSize items (1 to 200, 100 if unset) following
+// the item whose id is NextPageToken, or preceding the one whose id is PreviousPageToken.
+// StartDate and EndDate bound the items, when set.
+type PageParams struct {
+	NextPageToken     string    `url:"nextPageToken,omitempty"`
+	PreviousPageToken string    `url:"previousPageToken,omitempty"`
+	PageSize          int       `url:"pageSize,omitempty"`
+	StartDate         time.Time `url:"startDate,omitempty"`
+	EndDate           time.Time `url:"endDate,omitempty"`
+}
+
+// ClosedOrdersParams filters the closed orders.
+type ClosedOrdersParams struct {
+	MarketSymbol string `url:"marketSymbol,omitempty"`
+	PageParams
+}
+
+// pager walks the pages of a history endpoint, from the most recent item to the oldest.
+type pager struct {
+	ctx   context.Context
+	token string
+	done  bool
+	err   error
+	pos   int
+	size  int
+
+	// load fetches the page following token, keeps it and returns its length and last id.
+	load func(ctx context.Context, token string) (size int, last string, err error)
+}
+
+func newPager(ctx context.Context, token string, load func(context.Context, string) (int, string, error)) pager {
+	return pager{ctx: ctx, token: token, pos: -1, load: load}
+}
+
+// next moves to the next item, loading the next page when the current one is exhausted.
+func (p *pager) next() bool {
+	p.pos++
+	for p.pos >= p.size {
+		if p.done || p.err != nil {
+			return false
+		}
+		size, last, err := p.load(p.ctx, p.token)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.pos, p.size = 0, size
+		if size == 0 {
+			p.done = true
+		}
+		p.token = last
+	}
+	return true
+}
+
+// ClosedOrdersIterator walks closed orders across pages. Call Next before each Order:
+//
+//	it := b.ClosedOrdersIter(ctx, ClosedOrdersParams{MarketSymbol: "LTC-BTC"})
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ClosedOrdersIterator struct {
+	p    pager
+	page []OrderV3
+}
+
+// ClosedOrdersIter returns an iterator over the closed orders matching params, from the most
+// recent, starting after params.NextPageToken if set. params.PreviousPageToken is ignored.
+func (b *Bittrex) ClosedOrdersIter(ctx context.Context, params ClosedOrdersParams) *ClosedOrdersIterator {
+	it := &ClosedOrdersIterator{}
+	params.PreviousPageToken = ""
+	it.p = newPager(ctx, params.NextPageToken, func(ctx context.Context, token string) (int, string, error) {
+		params.NextPageToken = token
+		page, err := b.GetClosedOrdersPageCtx(ctx, params)
+		if err != nil || len(page) == 0 {
+			return 0, "", err
+		}
+		it.page = page
+		return len(page), page[len(page)-1].ID, nil
+	})
+	return it
+}
+
+// Next moves to the next order. It returns false at the end of the history or on error.
+func (it *ClosedOrdersIterator) Next() bool {
+	return it.p.next()
+}
+
+// Order returns the current order.
+func (it *ClosedOrdersIterator) Order() OrderV3 {
+	return it.page[it.p.pos]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ClosedOrdersIterator) Err() error {
+	return it.p.err
+}
+
+// ClosedWithdrawalsIterator walks closed withdrawals across pages, like ClosedOrdersIterator.
+type ClosedWithdrawalsIterator struct {
+	p    pager
+	page []WithdrawalV3
+}
+
+// ClosedWithdrawalsIter returns an iterator over the closed withdrawals matching params, from the
+// most recent, starting after params.NextPageToken if set. params.PreviousPageToken is ignored.
+func (b *Bittrex) ClosedWithdrawalsIter(ctx context.Context, params WithdrawalHistoryParams) *ClosedWithdrawalsIterator {
+	it := &ClosedWithdrawalsIterator{}
+	params.PreviousPageToken = ""
+	it.p = newPager(ctx, params.NextPageToken, func(ctx context.Context, token string) (int, string, error) {
+		params.NextPageToken = token
+		page, err := b.GetClosedWithdrawalsPageCtx(ctx, params)
+		if err != nil || len(page) == 0 {
+			return 0, "", err
+		}
+		it.page = page
+		return len(page), page[len(page)-1].ID, nil
+	})
+	return it
+}
+
+// Next moves to the next withdrawal. It returns false at the end of the history or on error.
+func (it *ClosedWithdrawalsIterator) Next() bool {
+	return it.p.next()
+}
+
+// Withdrawal returns the current withdrawal.
+func (it *ClosedWithdrawalsIterator) Withdrawal() WithdrawalV3 {
+	return it.page[it.p.pos]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ClosedWithdrawalsIterator) Err() error {
+	return it.p.err
+}
+
+// ClosedDepositsIterator walks closed deposits across pages, like ClosedOrdersIterator.
+type ClosedDepositsIterator struct {
+	p    pager
+	page []DepositV3
+}
+
+// ClosedDepositsIter returns an iterator over the closed deposits matching params, from the
+// most recent, starting after params.NextPageToken if set. params.PreviousPageToken is ignored.
+func (b *Bittrex) ClosedDepositsIter(ctx context.Context, params DepositHistoryParams) *ClosedDepositsIterator {
+	it := &ClosedDepositsIterator{}
+	params.PreviousPageToken = ""
+	it.p = newPager(ctx, params.NextPageToken, func(ctx context.Context, token string) (int, string, error) {
+		params.NextPageToken = token
+		page, err := b.GetClosedDepositsPageCtx(ctx, params)
+		if err != nil || len(page) == 0 {
+			return 0, "", err
+		}
+		it.page = page
+		return len(page), page[len(page)-1].ID, nil
+	})
+	return it
+}
+
+// Next moves to the next deposit. It returns false at the end of the history or on error.
+func (it *ClosedDepositsIterator) Next() bool {
+	return it.p.next()
+}
+
+// Deposit returns the current deposit.
+func (it *ClosedDepositsIterator) Deposit() DepositV3 {
+	return it.page[it.p.pos]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ClosedDepositsIterator) Err() error {
+	return it.p.err
+}
+
+// ExecutionsIterator walks executions across pages, like ClosedOrdersIterator.
+type ExecutionsIterator struct {
+	p    pager
+	page []ExecutionV3
+}
+
+// ExecutionsIter returns an iterator over the executions matching params, from the most
+// recent, starting after params.NextPageToken if set. params.PreviousPageToken is ignored.
+func (b *Bittrex) ExecutionsIter(ctx context.Context, params ExecutionsParams) *ExecutionsIterator {
+	it := &ExecutionsIterator{}
+	params.PreviousPageToken = ""
+	it.p = newPager(ctx, params.NextPageToken, func(ctx context.Context, token string) (int, string, error) {
+		params.NextPageToken = token
+		page, err := b.GetExecutionsCtx(ctx, params)
+		if err != nil || len(page) == 0 {
+			return 0, "", err
+		}
+		it.page = page
+		return len(page), page[len(page)-1].ID, nil
+	})
+	return it
+}
+
+// Next moves to the next execution. It returns false at the end of the history or on error.
+func (it *ExecutionsIterator) Next() bool {
+	return it.p.next()
+}
+
+// Execution returns the current execution.
+func (it *ExecutionsIterator) Execution() ExecutionV3 {
+	return it.page[it.p.pos]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ExecutionsIterator) Err() error {
+	return it.p.err
+}
@@ -0,0 +1,107 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func TestPagination(t *testing.T) {
+	srv, b := newTestServer(t)
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	srv.SetClock(func() time.Time { return now })
+	srv.SetBalance("BTC", d("1"))
+	srv.AddLiquidity("LTC-BTC", "SELL", d("0.004"), d("10"))
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Hour)
+		order, err := b.MarketBuy("LTC-BTC", d("1"))
+		assert.Nil(t, err)
+		ids = append([]string{order.ID}, ids...)
+		srv.AddDeposit(bittrextest.Deposit{CurrencySymbol: "LTC", Quantity: d("1")})
+	}
+
+	ctx := context.Background()
+	requests := len(srv.Requests())
+	it := b.ClosedOrdersIter(ctx, ClosedOrdersParams{PageParams: PageParams{PageSize: 2}})
+	var walked []string
+	for it.Next() {
+		walked = append(walked, it.Order().ID)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, ids, walked)
+	assert.Equal(t, 4, len(srv.Requests())-requests)
+
+	// Dates and tokens bound the history
+	page, err := b.GetClosedOrdersPage(ClosedOrdersParams{
+		MarketSymbol: "LTC-BTC",
+		PageParams: PageParams{
+			NextPageToken: ids[0],
+			StartDate:     time.Date(2021, 3, 1, 2, 0, 0, 0, time.UTC),
+		},
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, page, 3) {
+		assert.Equal(t, ids[1], page[0].ID)
+	}
+	page, err = b.GetClosedOrdersPage(ClosedOrdersParams{PageParams: PageParams{PreviousPageToken: ids[3], PageSize: 2}})
+	assert.Nil(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, ids[1], page[0].ID)
+	}
+
+	deposits := b.ClosedDepositsIter(ctx, DepositHistoryParams{CurrencySymbol: "LTC", PageParams: PageParams{PageSize: 3}})
+	count := 0
+	for deposits.Next() {
+		count++
+	}
+	assert.Nil(t, deposits.Err())
+	assert.Equal(t, 5, count)
+
+	withdrawals := b.ClosedWithdrawalsIter(ctx, WithdrawalHistoryParams{PageParams: PageParams{NextPageToken: "unknown"}})
+	assert.False(t, withdrawals.Next())
+	assert.Error(t, withdrawals.Err())
+}
+
+func TestPager(t *testing.T) {
+	ctx := context.Background()
+	pages := map[string][]string{"": {"a", "b"}, "b": {"c"}, "c": {}}
+	var tokens []string
+	p := newPager(ctx, "", func(_ context.Context, token string) (int, string, error) {
+		tokens = append(tokens, token)
+		page := pages[token]
+		if len(page) == 0 {
+			return 0, "", nil
+		}
+		return len(page), page[len(page)-1], nil
+	})
+	n := 0
+	for p.next() {
+		n++
+	}
+	assert.Equal(t, 3, n)
+	assert.Nil(t, p.err)
+	assert.Equal(t, []string{"", "b", "c"}, tokens)
+
+	// An empty page ends the walk for good
+	assert.False(t, p.next())
+	assert.Len(t, tokens, 3)
+
+	// So does an error, which is kept
+	failure := errors.New("down")
+	loads := 0
+	p = newPager(ctx, "token", func(context.Context, string) (int, string, error) {
+		loads++
+		return 0, "", failure
+	})
+	assert.False(t, p.next())
+	assert.False(t, p.next())
+	assert.Equal(t, failure, p.err)
+	assert.Equal(t, 1, loads)
+}
@@ -0,0 +1,196 @@
+package bittrex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups endpoints sharing the same request budget.
+type EndpointClass string
+
+const (
+	ENDPOINT_PUBLIC      EndpointClass = "PUBLIC"      // public market data
+	ENDPOINT_PRIVATE     EndpointClass = "PRIVATE"     // authenticated trading and account endpoints
+	ENDPOINT_WITHDRAWALS EndpointClass = "WITHDRAWALS" // withdrawal endpoints
+)
+
+// RateLimitPolicy tells the client what to do when a budget is exhausted.
+type RateLimitPolicy string
+
+const (
+	RATE_LIMIT_BLOCK     RateLimitPolicy = "BLOCK"     // wait for the budget to refill
+	RATE_LIMIT_FAIL_FAST RateLimitPolicy = "FAIL_FAST" // fail with an error matching ERR_RATE_LIMITED
+)
+
+// RateLimit is a token bucket allowing Requests per Per, with bursts up to Burst requests.
+// The zero value disables limiting.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// DefaultRateLimits follow the Bittrex v3 quota of 60 requests per minute. Clients only
+// apply them when created with WithRateLimits(DefaultRateLimits, policy).
+var DefaultRateLimits = map[EndpointClass]RateLimit{
+	ENDPOINT_PUBLIC:      {Requests: 60, Per: time.Minute, Burst: 10},
+	ENDPOINT_PRIVATE:     {Requests: 60, Per: time.Minute, Burst: 10},
+	ENDPOINT_WITHDRAWALS: {Requests: 10, Per: time.Minute, Burst: 1},
+}
+
+// RateLimitUsage is a snapshot of the budget of an endpoint class.
+type RateLimitUsage struct {
+	Class     EndpointClass
+	Limit     RateLimit
+	Available float64 // tokens left in the bucket
+	Queued    int     // requests waiting for a token
+	Requests  uint64  // requests let through since the client was created
+	Throttled uint64  // requests that waited or were rejected
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// interval returns the time needed to refill one token.
+func (l RateLimit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+func (l RateLimit) burst() float64 {
+	if l.Burst < 1 {
+		return 1
+	}
+	return float64(l.Burst)
+}
+
+type tokenBucket struct {
+	limit     RateLimit
+	tokens    float64
+	last      time.Time
+	queued    int
+	requests  uint64
+	throttled uint64
+}
+
+// refill adds the tokens earned since the last call.
+func (tb *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(tb.last); elapsed > 0 {
+		tb.tokens += float64(elapsed) / float64(tb.limit.interval())
+		if tb.tokens > tb.limit.burst() {
+			tb.tokens = tb.limit.burst()
+		}
+	}
+	tb.last = now
+}
+
+// rateLimiter holds a token bucket per endpoint class.
+type rateLimiter struct {
+	mu      sync.Mutex
+	policy  RateLimitPolicy
+	buckets map[EndpointClass]*tokenBucket
+	clock   Clock
+}
+
+// newRateLimiter returns a limiter whose buckets start full at the time of clock.
+func newRateLimiter(limits map[EndpointClass]RateLimit, clock Clock) *rateLimiter {
+	rl := &rateLimiter{
+		policy:  RATE_LIMIT_BLOCK,
+		buckets: make(map[EndpointClass]*tokenBucket),
+		clock:   clock,
+	}
+	for class, limit := range limits {
+		rl.setLimit(class, limit)
+	}
+	return rl
+}
+
+func (rl *rateLimiter) setLimit(class EndpointClass, limit RateLimit) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.buckets[class] = &tokenBucket{limit: limit, tokens: limit.burst(), last: rl.clock.Now()}
+}
+
+func (rl *rateLimiter) setPolicy(policy RateLimitPolicy) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.policy = policy
+}
+
+// wait takes a token from the bucket of class, blocking until one is available
+// or failing fast depending on the policy.
+func (rl *rateLimiter) wait(ctx context.Context, class EndpointClass) error {
+	rl.mu.Lock()
+	tb, ok := rl.buckets[class]
+	if !ok || !tb.limit.enabled() {
+		rl.mu.Unlock()
+		return nil
+	}
+	tb.refill(rl.clock.Now())
+	if tb.tokens >= 1 {
+		tb.tokens--
+		tb.requests++
+		rl.mu.Unlock()
+		return nil
+	}
+	tb.throttled++
+	if rl.policy == RATE_LIMIT_FAIL_FAST {
+		rl.mu.Unlock()
+		return fmt.Errorf("%w: client side budget of %s endpoints exhausted", ERR_RATE_LIMITED, strings.ToLower(string(class)))
+	}
+	// Reserve the token now so that waiting requests are served in order
+	tb.tokens--
+	tb.queued++
+	wait := time.Duration(-tb.tokens * float64(tb.limit.interval()))
+	rl.mu.Unlock()
+
+	err := sleepCtx(ctx, rl.clock, wait)
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	tb.queued--
+	if err != nil {
+		// Give back the reservation
+		tb.tokens++
+		return err
+	}
+	tb.requests++
+	return nil
+}
+
+// usage returns the current budget of class.
+func (rl *rateLimiter) usage(class EndpointClass) RateLimitUsage {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	u := RateLimitUsage{Class: class}
+	tb, ok := rl.buckets[class]
+	if !ok {
+		return u
+	}
+	if tb.limit.enabled() {
+		tb.refill(rl.clock.Now())
+	}
+	u.Limit = tb.limit
+	if tb.tokens > 0 {
+		u.Available = tb.tokens
+	}
+	u.Queued = tb.queued
+	u.Requests = tb.requests
+	u.Throttled = tb.throttled
+	return u
+}
+
+// endpointClass returns the budget a request is charged to.
+func endpointClass(resource string, authNeeded bool) EndpointClass {
+	switch {
+	case strings.HasPrefix(resource, "withdrawals"):
+		return ENDPOINT_WITHDRAWALS
+	case authNeeded:
+		return ENDPOINT_PRIVATE
+	default:
+		return ENDPOINT_PUBLIC
+	}
+}
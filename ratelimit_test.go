@@ -0,0 +1,90 @@
+package bittrex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterFailFast(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	rl := newRateLimiter(nil, clock)
+	rl.setLimit(ENDPOINT_PUBLIC, RateLimit{Requests: 1, Per: 100 * time.Millisecond, Burst: 2})
+	rl.setPolicy(RATE_LIMIT_FAIL_FAST)
+
+	ctx := context.Background()
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PUBLIC))
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PUBLIC))
+	err := rl.wait(ctx, ENDPOINT_PUBLIC)
+	assert.True(t, IsRateLimited(err))
+
+	// Unconfigured classes are not limited
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PRIVATE))
+
+	clock.now = clock.now.Add(100 * time.Millisecond)
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PUBLIC))
+
+	u := rl.usage(ENDPOINT_PUBLIC)
+	assert.Equal(t, uint64(3), u.Requests)
+	assert.Equal(t, uint64(1), u.Throttled)
+	assert.Equal(t, float64(0), u.Available)
+
+	clock.now = clock.now.Add(time.Second)
+	assert.Equal(t, float64(2), rl.usage(ENDPOINT_PUBLIC).Available)
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	rl := newRateLimiter(map[EndpointClass]RateLimit{
+		ENDPOINT_PRIVATE: {Requests: 10, Per: 100 * time.Millisecond, Burst: 1},
+	}, systemClock{})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, rl.wait(context.Background(), ENDPOINT_PRIVATE))
+	}
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	// A cancelled wait gives its reservation back
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, rl.wait(ctx, ENDPOINT_PRIVATE))
+	assert.Equal(t, 0, rl.usage(ENDPOINT_PRIVATE).Queued)
+	assert.Equal(t, uint64(5), rl.usage(ENDPOINT_PRIVATE).Requests)
+}
+
+func TestEndpointClass(t *testing.T) {
+	assert.Equal(t, ENDPOINT_PUBLIC, endpointClass("markets", false))
+	assert.Equal(t, ENDPOINT_PRIVATE, endpointClass("orders", true))
+	assert.Equal(t, ENDPOINT_WITHDRAWALS, endpointClass("withdrawals/open", true))
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time                         { return c.now }
+func (c *fakeClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func TestClientNotLimitedByDefault(t *testing.T) {
+	b := New("key", "secret")
+	assert.False(t, b.RateLimitUsage(ENDPOINT_PUBLIC).Limit.enabled())
+
+	b = New("key", "secret", WithRateLimits(DefaultRateLimits, RATE_LIMIT_BLOCK))
+	assert.Equal(t, DefaultRateLimits[ENDPOINT_PUBLIC], b.RateLimitUsage(ENDPOINT_PUBLIC).Limit)
+}
+
+func TestClientRateLimitsUseClock(t *testing.T) {
+	// The buckets refill on the clock of the client, whatever the order of the options
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	limits := map[EndpointClass]RateLimit{ENDPOINT_PUBLIC: {Requests: 1, Per: time.Second, Burst: 1}}
+	b := New("key", "secret", WithRateLimits(limits, RATE_LIMIT_FAIL_FAST), WithClock(clock))
+	rl := b.client.limiter
+
+	ctx := context.Background()
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PUBLIC))
+	assert.True(t, IsRateLimited(rl.wait(ctx, ENDPOINT_PUBLIC)))
+	clock.now = clock.now.Add(time.Second)
+	assert.Nil(t, rl.wait(ctx, ENDPOINT_PUBLIC))
+}
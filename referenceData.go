@@ -0,0 +1,298 @@
+package bittrex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReferenceDataTTL is how long the reference data cache trusts markets and currencies.
+const DefaultReferenceDataTTL = 10 * time.Minute
+
+// MarketChange is a change of a market seen when the reference data is refreshed: a new
+// status or notice, a listing (zero Previous) or a delisting (zero Current).
+type MarketChange struct {
+	Previous MarketV3
+	Current  MarketV3
+}
+
+// Symbol returns the symbol of the market that changed.
+func (c MarketChange) Symbol() string {
+	if c.Current.Symbol != "" {
+		return c.Current.Symbol
+	}
+	return c.Previous.Symbol
+}
+
+// ReferenceData caches markets and currencies for lookups on hot paths. They are loaded on
+// first use and refreshed once older than the TTL set by WithReferenceDataTTL, or on demand.
+// A client has one cache, shared by its subaccount handles and returned by Bittrex.ReferenceData.
+type ReferenceData struct {
+	b   *Bittrex
+	ttl time.Duration
+
+	fetch sync.Mutex // serializes loads, so concurrent lookups fetch once
+
+	mu           sync.Mutex
+	markets      map[string]MarketV3
+	marketsAt    time.Time
+	currencies   map[string]CurrencyV3
+	currenciesAt time.Time
+	subscribers  map[int]func(MarketChange)
+	nextID       int
+}
+
+func newReferenceData(b *Bittrex, ttl time.Duration) *ReferenceData {
+	return &ReferenceData{b: b, ttl: ttl, subscribers: make(map[int]func(MarketChange))}
+}
+
+// ReferenceData returns the reference data cache of the client.
+func (b *Bittrex) ReferenceData() *ReferenceData {
+	return b.client.refData
+}
+
+// SubscribeMarketChanges calls f with the markets that changed on each refresh, from the
+// goroutine refreshing, once the refresh is over: f may use the cache, or refresh it. The
+// first load reports no change. It returns a function removing f.
+func (r *ReferenceData) SubscribeMarketChanges(f func(MarketChange)) (unsubscribe func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.subscribers[id] = f
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subscribers, id)
+	}
+}
+
+// Market returns a market by symbol, or ERR_NOT_FOUND.
+func (r *ReferenceData) Market(ctx context.Context, symbol string) (MarketV3, error) {
+	if err := r.loadMarkets(ctx, false); err != nil {
+		return MarketV3{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.markets[normalizeSymbol(symbol)]
+	if !ok {
+		return m, fmt.Errorf("%w: market %s", ERR_NOT_FOUND, symbol)
+	}
+	return m, nil
+}
+
+// listed reports whether m is a market of the cache. It does not load it: a cache not loaded
+// yet lists nothing.
+func (r *ReferenceData) listed(m MarketSymbol) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.markets[string(m)]
+	return ok
+}
+
+// MarketByCurrencies returns the market trading base against quote, e.g. LTC and BTC for
+// LTC-BTC, or ERR_NOT_FOUND.
+func (r *ReferenceData) MarketByCurrencies(ctx context.Context, base, quote string) (MarketV3, error) {
+	markets, err := r.filterMarkets(ctx, func(m MarketV3) bool {
+		return strings.EqualFold(m.BaseCurrencySymbol, base) && strings.EqualFold(m.QuoteCurrencySymbol, quote)
+	})
+	if err != nil {
+		return MarketV3{}, err
+	}
+	if len(markets) == 0 {
+		return MarketV3{}, fmt.Errorf("%w: market %s-%s", ERR_NOT_FOUND, base, quote)
+	}
+	return markets[0], nil
+}
+
+// Markets returns every market, sorted by symbol.
+func (r *ReferenceData) Markets(ctx context.Context) ([]MarketV3, error) {
+	return r.filterMarkets(ctx, func(MarketV3) bool { return true })
+}
+
+// MarketsByBase returns the markets whose base currency is currency, sorted by symbol.
+func (r *ReferenceData) MarketsByBase(ctx context.Context, currency string) ([]MarketV3, error) {
+	return r.filterMarkets(ctx, func(m MarketV3) bool { return strings.EqualFold(m.BaseCurrencySymbol, currency) })
+}
+
+// MarketsByQuote returns the markets whose quote currency is currency, sorted by symbol.
+func (r *ReferenceData) MarketsByQuote(ctx context.Context, currency string) ([]MarketV3, error) {
+	return r.filterMarkets(ctx, func(m MarketV3) bool { return strings.EqualFold(m.QuoteCurrencySymbol, currency) })
+}
+
+func (r *ReferenceData) filterMarkets(ctx context.Context, keep func(MarketV3) bool) ([]MarketV3, error) {
+	if err := r.loadMarkets(ctx, false); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var markets []MarketV3
+	for _, m := range r.markets {
+		if keep(m) {
+			markets = append(markets, m)
+		}
+	}
+	sort.Slice(markets, func(i, j int) bool { return markets[i].Symbol < markets[j].Symbol })
+	return markets, nil
+}
+
+// Currency returns a currency by symbol, or ERR_NOT_FOUND.
+func (r *ReferenceData) Currency(ctx context.Context, symbol string) (CurrencyV3, error) {
+	if err := r.loadCurrencies(ctx, false); err != nil {
+		return CurrencyV3{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.currencies[normalizeSymbol(symbol)]
+	if !ok {
+		return c, fmt.Errorf("%w: currency %s", ERR_NOT_FOUND, symbol)
+	}
+	return c, nil
+}
+
+// Currencies returns every currency, sorted by symbol.
+func (r *ReferenceData) Currencies(ctx context.Context) ([]CurrencyV3, error) {
+	if err := r.loadCurrencies(ctx, false); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	currencies := make([]CurrencyV3, 0, len(r.currencies))
+	for _, c := range r.currencies {
+		currencies = append(currencies, c)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Symbol < currencies[j].Symbol })
+	return currencies, nil
+}
+
+// Refresh reloads markets and currencies, whatever their age.
+func (r *ReferenceData) Refresh(ctx context.Context) error {
+	if err := r.RefreshMarkets(ctx); err != nil {
+		return err
+	}
+	return r.loadCurrencies(ctx, true)
+}
+
+// RefreshMarkets reloads markets, whatever their age, and notifies their changes.
+func (r *ReferenceData) RefreshMarkets(ctx context.Context) error {
+	return r.loadMarkets(ctx, true)
+}
+
+// fresh reports whether data loaded at loadedAt is still within the TTL.
+func (r *ReferenceData) fresh(loadedAt time.Time) bool {
+	return !loadedAt.IsZero() && r.b.client.clock.Now().Sub(loadedAt) < r.ttl
+}
+
+// loadMarkets fetches markets if forced or expired, then notifies their changes once the
+// load is over, so that subscribers may use the cache.
+func (r *ReferenceData) loadMarkets(ctx context.Context, force bool) error {
+	changes, subscribers, err := r.fetchMarkets(ctx, force)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		for _, f := range subscribers {
+			f(c)
+		}
+	}
+	return nil
+}
+
+// fetchMarkets fetches markets if forced or expired. It returns their changes and the
+// subscribers to notify.
+func (r *ReferenceData) fetchMarkets(ctx context.Context, force bool) ([]MarketChange, []func(MarketChange), error) {
+	r.mu.Lock()
+	loadedAt := r.marketsAt
+	r.mu.Unlock()
+	if !force && r.fresh(loadedAt) {
+		return nil, nil, nil
+	}
+
+	r.fetch.Lock()
+	defer r.fetch.Unlock()
+	r.mu.Lock()
+	reloaded := r.marketsAt != loadedAt
+	r.mu.Unlock()
+	if reloaded && (force || r.fresh(r.marketsAt)) {
+		// Loaded by a concurrent call while waiting
+		return nil, nil, nil
+	}
+
+	list, err := r.b.GetMarketsCtx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	markets := make(map[string]MarketV3, len(list))
+	for _, m := range list {
+		markets[m.Symbol] = m
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var changes []MarketChange
+	if r.markets != nil {
+		changes = marketChanges(r.markets, markets)
+	}
+	r.markets, r.marketsAt = markets, r.b.client.clock.Now()
+	subscribers := make([]func(MarketChange), 0, len(r.subscribers))
+	for id := 0; id < r.nextID; id++ {
+		if f, ok := r.subscribers[id]; ok {
+			subscribers = append(subscribers, f)
+		}
+	}
+	return changes, subscribers, nil
+}
+
+// loadCurrencies fetches currencies if forced or expired.
+func (r *ReferenceData) loadCurrencies(ctx context.Context, force bool) error {
+	r.mu.Lock()
+	loadedAt := r.currenciesAt
+	r.mu.Unlock()
+	if !force && r.fresh(loadedAt) {
+		return nil
+	}
+
+	r.fetch.Lock()
+	defer r.fetch.Unlock()
+	r.mu.Lock()
+	reloaded := r.currenciesAt != loadedAt
+	r.mu.Unlock()
+	if reloaded && (force || r.fresh(r.currenciesAt)) {
+		return nil
+	}
+
+	list, err := r.b.GetCurrenciesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	currencies := make(map[string]CurrencyV3, len(list))
+	for _, c := range list {
+		currencies[c.Symbol] = c
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.currencies, r.currenciesAt = currencies, r.b.client.clock.Now()
+	return nil
+}
+
+// marketChanges returns the markets listed, delisted, or whose status or notice changed,
+// sorted by symbol.
+func marketChanges(previous, current map[string]MarketV3) []MarketChange {
+	var changes []MarketChange
+	for symbol, m := range current {
+		p, ok := previous[symbol]
+		if !ok || p.Status != m.Status || p.Notice != m.Notice {
+			changes = append(changes, MarketChange{Previous: p, Current: m})
+		}
+	}
+	for symbol, p := range previous {
+		if _, ok := current[symbol]; !ok {
+			changes = append(changes, MarketChange{Previous: p})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Symbol() < changes[j].Symbol() })
+	return changes
+}
@@ -0,0 +1,97 @@
+package bittrex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceData(t *testing.T) {
+	srv := bittrextest.NewServer("key", "secret")
+	defer srv.Close()
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	srv.AddMarket(bittrextest.Market{Symbol: "ETH-BTC", BaseCurrencySymbol: "ETH", QuoteCurrencySymbol: "BTC", Precision: 8})
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-USD", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "USD", Precision: 3})
+
+	clock := &fakeClock{now: time.Now()}
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithClock(clock), WithRetryPolicy(NoRetry), WithReferenceDataTTL(time.Minute))
+	ref := b.ReferenceData()
+	ctx := context.Background()
+
+	var changes []MarketChange
+	unsubscribe := ref.SubscribeMarketChanges(func(c MarketChange) { changes = append(changes, c) })
+
+	m, err := ref.Market(ctx, "ltc-usd")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), m.Precision)
+	_, err = ref.Market(ctx, "DOGE-BTC")
+	assert.True(t, IsNotFound(err))
+	m, err = ref.MarketByCurrencies(ctx, "eth", "btc")
+	assert.Nil(t, err)
+	assert.Equal(t, "ETH-BTC", m.Symbol)
+	markets, err := ref.MarketsByBase(ctx, "LTC")
+	assert.Nil(t, err)
+	assert.Len(t, markets, 2)
+	markets, err = ref.MarketsByQuote(ctx, "BTC")
+	assert.Nil(t, err)
+	if assert.Len(t, markets, 2) {
+		assert.Equal(t, "ETH-BTC", markets[0].Symbol)
+	}
+	c, err := ref.Currency(ctx, "ltc")
+	assert.Nil(t, err)
+	assert.Equal(t, "LTC", c.Symbol)
+	assert.Len(t, srv.Requests(), 2) // one load of each
+	assert.Empty(t, changes)
+
+	// Changes show once the TTL expires
+	srv.SetMarketStatus("LTC-BTC", "OFFLINE", "maintenance")
+	m, _ = ref.Market(ctx, "LTC-BTC")
+	assert.Equal(t, "ONLINE", m.Status)
+	clock.now = clock.now.Add(time.Minute)
+	m, _ = ref.Market(ctx, "LTC-BTC")
+	assert.Equal(t, "OFFLINE", m.Status)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "LTC-BTC", changes[0].Symbol())
+		assert.Equal(t, "ONLINE", changes[0].Previous.Status)
+		assert.Equal(t, "maintenance", changes[0].Current.Notice)
+	}
+
+	// Or on demand
+	srv.SetMarketStatus("LTC-BTC", "ONLINE", "")
+	unsubscribe()
+	assert.Nil(t, ref.RefreshMarkets(ctx))
+	m, _ = ref.Market(ctx, "LTC-BTC")
+	assert.Equal(t, "ONLINE", m.Status)
+	assert.Len(t, changes, 1)
+}
+
+func TestReferenceDataSubscriberRefreshes(t *testing.T) {
+	srv := bittrextest.NewServer("key", "secret")
+	defer srv.Close()
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", Precision: 8})
+	ref := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry)).ReferenceData()
+	ctx := context.Background()
+	assert.Nil(t, ref.RefreshMarkets(ctx))
+
+	// A subscriber reloading the cache does not deadlock
+	var statuses []string
+	ref.SubscribeMarketChanges(func(c MarketChange) {
+		assert.Nil(t, ref.Refresh(ctx))
+		m, err := ref.Market(ctx, c.Symbol())
+		assert.Nil(t, err)
+		statuses = append(statuses, m.Status)
+	})
+	srv.SetMarketStatus("LTC-BTC", "OFFLINE", "")
+	done := make(chan error, 1)
+	go func() { done <- ref.RefreshMarkets(ctx) }()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("RefreshMarkets deadlocked")
+	}
+	assert.Equal(t, []string{"OFFLINE"}, statuses)
+}
@@ -0,0 +1,104 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries idempotent requests (GET requests and
+// order creations carrying a ClientOrderID) after a network error, a 5xx or a 429.
+type RetryPolicy struct {
+	MaxAttempts    int           // total number of attempts, including the first one. <= 1 disables retries
+	InitialBackoff time.Duration // wait before the first retry
+	MaxBackoff     time.Duration // upper bound of the wait between two attempts
+	Multiplier     float64       // backoff growth factor between attempts
+	Jitter         float64       // fraction [0, 1] of each wait that is randomised
+}
+
+var (
+	// DefaultRetryPolicy is used by clients unless configured otherwise
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+	// NoRetry disables retries
+	NoRetry = RetryPolicy{MaxAttempts: 1}
+)
+
+// backoff returns the wait before the given retry (1 for the first retry).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		d -= d * jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// shouldRetry reports whether err is transient. The returned duration is the wait
+// requested by the server through the Retry-After header, if any.
+func shouldRetry(err error, now time.Time) (bool, time.Duration) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 {
+			return true, parseRetryAfter(apiErr.Header.Get("Retry-After"), now)
+		}
+		return false, 0
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || errors.Is(err, context.Canceled) {
+		return false, 0
+	}
+	// Timeouts, temporary network errors and connections dropped by the server are
+	// transient; a malformed URL or a refused connection are not
+	if urlErr.Timeout() || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, 0
+	}
+	var netErr net.Error
+	return errors.As(urlErr.Err, &netErr) && netErr.Temporary(), 0
+}
+
+// parseRetryAfter parses a Retry-After header value, either in seconds or as an HTTP date.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepCtx waits for d on clock, or returns ctx.Err() if ctx is done first.
+func sleepCtx(ctx context.Context, clock Clock, d time.Duration) error {
+	select {
+	case <-clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
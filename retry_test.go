@@ -0,0 +1,126 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("", "")
+	c.retryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+
+	r, err := c.do("GET", srv.URL, "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", string(r))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// POST requests are not idempotent unless asked to
+	atomic.StoreInt32(&calls, 0)
+	_, err = c.do("POST", srv.URL, "{}", false)
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Give up after MaxAttempts
+	atomic.StoreInt32(&calls, -10)
+	_, err = c.do("GET", srv.URL, "", false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(-7), atomic.LoadInt32(&calls))
+}
+
+func TestClientNoRetryOnClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := NewClient("", "")
+	c.retryPolicy = RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}
+	_, err := c.do("GET", srv.URL, "", false)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, p.backoff(3))
+	assert.Equal(t, time.Second, p.backoff(10))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond)
+	}
+
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2", time.Now()))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", time.Now()))
+	assert.True(t, parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), time.Now()) > 50*time.Second)
+}
+
+func TestClientRetryAfterCapped(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := NewClient("", "")
+	c.retryPolicy = RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	start := time.Now()
+	_, err := c.do("GET", srv.URL, "", false)
+	assert.Nil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestShouldRetry(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://api.bittrex.com/v3/markets", Err: err}
+	}
+	for err, want := range map[error]bool{
+		urlErr(context.DeadlineExceeded):                                    true,
+		urlErr(&net.OpError{Op: "read", Err: syscall.ECONNRESET}):           true,
+		urlErr(io.ErrUnexpectedEOF):                                         true,
+		urlErr(context.Canceled):                                            false,
+		urlErr(errors.New("unsupported protocol scheme \"ftp\"")):           false,
+		urlErr(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}):         false,
+		&APIError{StatusCode: http.StatusBadGateway, Header: http.Header{}}: true,
+		&APIError{StatusCode: http.StatusNotFound, Header: http.Header{}}:   false,
+		errors.New("decoding failed"):                                       false,
+	} {
+		retry, _ := shouldRetry(err, time.Now())
+		assert.Equal(t, want, retry, err.Error())
+	}
+}
@@ -0,0 +1,259 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// hubCaller invokes hub methods. Both signalrClient and the signalr.Client taken by
+// Authentication satisfy it.
+type hubCaller interface {
+	CallHub(hub, method string, params ...interface{}) (json.RawMessage, error)
+}
+
+// hubCallTimeout bounds a hub call the server never answers.
+const hubCallTimeout = 30 * time.Second
+
+// signalrClient is a SignalR 1.5 websocket client. It negotiates and dials with the http
+// client and websocket dialer of the connector, so their TLS settings come from
+// WithWebsocketTLSConfig rather than from the process wide defaults.
+type signalrClient struct {
+	OnMessageError func(err error)
+	OnClientMethod func(hub, method string, arguments []json.RawMessage)
+	// DisconnectedChannel is closed once the connection is lost. Valid after Connect.
+	DisconnectedChannel chan bool
+
+	httpClient *http.Client
+	dialer     *websocket.Dialer
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	socket  *websocket.Conn // nil until Connect succeeds
+	nextID  int
+	futures map[string]chan signalrMessage // pending hub calls by identifier
+	closed  bool
+}
+
+// signalrMessage is a message of the server: a hub call result or client method calls.
+type signalrMessage struct {
+	Cursor     string            `json:"C"`
+	Data       []json.RawMessage `json:"M"`
+	Result     json.RawMessage   `json:"R"`
+	Identifier string            `json:"I"`
+	Error      string            `json:"E"`
+}
+
+// newSignalrClient returns a client dialing with the TLS configuration of the connector.
+func (c *client) newSignalrClient() *signalrClient {
+	httpClient, dialer := http.DefaultClient, websocket.DefaultDialer
+	if c.wsTLSConfig != nil {
+		httpClient = &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: c.wsTLSConfig.Clone(),
+		}}
+		d := *websocket.DefaultDialer
+		d.TLSClientConfig = c.wsTLSConfig.Clone()
+		dialer = &d
+	}
+	return &signalrClient{
+		httpClient: httpClient,
+		dialer:     dialer,
+		nextID:     1,
+		futures:    make(map[string]chan signalrMessage),
+	}
+}
+
+// Connect negotiates a connection token over scheme (https), then opens the websocket to
+// hubs and starts dispatching the messages of the server.
+func (s *signalrClient) Connect(scheme, host string, hubs []string) error {
+	token, err := s.negotiate(scheme, host)
+	if err != nil {
+		return err
+	}
+
+	connectionData := make([]struct {
+		Name string `json:"Name"`
+	}, len(hubs))
+	for i, h := range hubs {
+		connectionData[i].Name = h
+	}
+	data, err := json.Marshal(connectionData)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("transport", "webSockets")
+	params.Set("clientProtocol", "1.5")
+	params.Set("connectionToken", token)
+	params.Set("connectionData", string(data))
+	u := url.URL{Scheme: "wss", Host: host, Path: "signalr/connect", RawQuery: params.Encode()}
+
+	socket, _, err := s.dialer.Dial(u.String(), nil)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.socket = socket
+	s.mu.Unlock()
+	s.DisconnectedChannel = make(chan bool)
+	go s.dispatch(socket)
+	return nil
+}
+
+// negotiate returns the connection token given by the server.
+func (s *signalrClient) negotiate(scheme, host string) (string, error) {
+	u := url.URL{Scheme: scheme, Host: host, Path: "/signalr/negotiate"}
+	resp, err := s.httpClient.Get(u.String())
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("signalr negotiate: %s", resp.Status)
+	}
+	var negotiation struct {
+		ConnectionToken string
+	}
+	if err = json.Unmarshal(body, &negotiation); err != nil {
+		return "", err
+	}
+	return negotiation.ConnectionToken, nil
+}
+
+// dispatch reads the messages of the server until the connection is lost.
+func (s *signalrClient) dispatch(socket *websocket.Conn) {
+	defer func() {
+		s.mu.Lock()
+		s.closed = true
+		for id, future := range s.futures {
+			close(future)
+			delete(s.futures, id)
+		}
+		s.mu.Unlock()
+		close(s.DisconnectedChannel)
+	}()
+
+	for {
+		_, data, err := socket.ReadMessage()
+		if err != nil {
+			socket.Close()
+			return
+		}
+		var message signalrMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			if s.OnMessageError != nil {
+				s.OnMessageError(err)
+			}
+			continue
+		}
+		if message.Identifier != "" {
+			s.mu.Lock()
+			future, ok := s.futures[message.Identifier]
+			delete(s.futures, message.Identifier)
+			s.mu.Unlock()
+			if ok {
+				future <- message
+				close(future)
+			}
+			continue
+		}
+		for _, m := range message.Data {
+			var call struct {
+				Hub       string            `json:"H"`
+				Method    string            `json:"M"`
+				Arguments []json.RawMessage `json:"A"`
+			}
+			if json.Unmarshal(m, &call) == nil && call.Hub != "" && call.Method != "" && s.OnClientMethod != nil {
+				s.OnClientMethod(call.Hub, call.Method, call.Arguments)
+			}
+		}
+	}
+}
+
+// CallHub invokes a hub method and waits for its result for up to hubCallTimeout, or fails
+// once the connection is lost.
+func (s *signalrClient) CallHub(hub, method string, params ...interface{}) (json.RawMessage, error) {
+	return s.CallHubCtx(context.Background(), hub, method, params...)
+}
+
+// CallHubCtx is the context-aware variant of CallHub. The wait is bounded by hubCallTimeout
+// even if ctx has no deadline.
+func (s *signalrClient) CallHubCtx(ctx context.Context, hub, method string, params ...interface{}) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, hubCallTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	if s.socket == nil || s.closed {
+		s.mu.Unlock()
+		return nil, errors.New("signalr: not connected")
+	}
+	socket := s.socket
+	id := s.nextID
+	s.nextID++
+	key := strconv.Itoa(id)
+	future := make(chan signalrMessage, 1)
+	s.futures[key] = future
+	s.mu.Unlock()
+
+	data, err := json.Marshal(struct {
+		Hub        string        `json:"H"`
+		Method     string        `json:"M"`
+		Arguments  []interface{} `json:"A"`
+		Identifier int           `json:"I"`
+	}{hub, method, params, id})
+	if err == nil {
+		s.writeMu.Lock()
+		err = socket.WriteMessage(websocket.TextMessage, data)
+		s.writeMu.Unlock()
+	}
+	if err != nil {
+		s.mu.Lock()
+		delete(s.futures, key)
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	var response signalrMessage
+	var ok bool
+	select {
+	case response, ok = <-future:
+	case <-ctx.Done():
+		s.mu.Lock()
+		delete(s.futures, key)
+		s.mu.Unlock()
+		return nil, fmt.Errorf("signalr: %s.%s: %w", hub, method, ctx.Err())
+	}
+	if !ok {
+		return nil, errors.New("signalr: call to server returned no result")
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Result, nil
+}
+
+// Close closes the connection, which ends the dispatch and pending calls. It does nothing if
+// Connect did not succeed.
+func (s *signalrClient) Close() {
+	s.mu.Lock()
+	socket := s.socket
+	s.mu.Unlock()
+	if socket != nil {
+		socket.Close()
+	}
+}
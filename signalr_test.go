@@ -0,0 +1,63 @@
+package bittrex
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSilentHub returns a SignalR server that accepts connections but never answers a call.
+func newSilentHub(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/negotiate") {
+			w.Write([]byte(`{"ConnectionToken":"token"}`))
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSignalrCallTimeout(t *testing.T) {
+	srv := newSilentHub(t)
+	c := newClient("", "", WithWebsocketTLSConfig(srv.Client().Transport.(*http.Transport).TLSClientConfig))
+	s := c.newSignalrClient()
+	if err := s.Connect("https", strings.TrimPrefix(srv.URL, "https://"), []string{WS_HUB}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := s.CallHubCtx(ctx, WS_HUB, "Subscribe", []string{"heartbeat"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	s.mu.Lock()
+	assert.Empty(t, s.futures)
+	s.mu.Unlock()
+}
+
+func TestSignalrNotConnected(t *testing.T) {
+	s := newClient("", "", WithWebsocketTLSConfig(&tls.Config{})).newSignalrClient()
+	assert.NotPanics(t, s.Close)
+	_, err := s.CallHub(WS_HUB, "Subscribe")
+	assert.Error(t, err)
+}
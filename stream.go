@@ -0,0 +1,719 @@
+package bittrex
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ERR_STREAM_CLOSED            = errors.New("stream closed")
+	ERR_STREAM_DISCONNECTED      = errors.New("stream disconnected")
+	ERR_STREAM_HEARTBEAT_TIMEOUT = errors.New("stream heartbeat timeout")
+)
+
+// STREAM_GAP is the method handlers receive, with a nil payload, once a reconnection restored
+// their channel: messages published during the outage are lost and state built from the
+// channel (order book, open orders, balances) must be resynchronised. Gaps are sent from the
+// goroutine supervising the stream, possibly while the new connection delivers messages.
+const STREAM_GAP = "gap"
+
+// DefaultReconnectPolicy is a reconnection policy retrying forever, up to one minute apart.
+var DefaultReconnectPolicy = RetryPolicy{
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// StreamHandler receives the decoded JSON payload of the messages of a channel.
+// method is the hub method of the message, e.g. ORDERBOOK or HEARTBEAT, or STREAM_GAP.
+//
+// Handlers run on the goroutine reading the connection: they must return quickly
+// and must not call Subscribe or Unsubscribe synchronously.
+type StreamHandler func(method string, payload json.RawMessage)
+
+// StreamOption configures a stream opened with OpenStream.
+type StreamOption func(*Stream)
+
+// WithReconnect makes the stream reconnect after the connection is lost, waiting between
+// attempts as policy says. policy.MaxAttempts bounds the attempts per outage, <= 0 retries forever.
+// Once reconnected the stream authenticates again if needed, restores every subscription and
+// sends STREAM_GAP to the handler of every channel.
+func WithReconnect(policy RetryPolicy) StreamOption {
+	return func(s *Stream) {
+		s.reconnect = &policy
+	}
+}
+
+// WithHeartbeatTimeout subscribes to the heartbeat channel and considers the connection lost
+// when no message is received for timeout.
+func WithHeartbeatTimeout(timeout time.Duration) StreamOption {
+	return func(s *Stream) {
+		s.heartbeatTimeout = timeout
+	}
+}
+
+// WithGapHandler sets a func called with every channel restored by a reconnection,
+// after its handler received STREAM_GAP.
+func WithGapHandler(f func(channel string)) StreamOption {
+	return func(s *Stream) {
+		s.onGap = f
+	}
+}
+
+// Stream multiplexes websocket subscriptions over a single SignalR connection.
+// Channels (ticker_LTC-BTC, orderbook_LTC-BTC_25, order, balance...) are added and
+// removed at runtime and their messages are routed to per channel handlers.
+type Stream struct {
+	b                *Bittrex
+	reconnect        *RetryPolicy // nil disables reconnection
+	heartbeatTimeout time.Duration
+	onGap            func(channel string)
+
+	ctx    context.Context // done once Close is called
+	cancel context.CancelFunc
+
+	callMu sync.Mutex // hub calls run one at a time, so subscriptions apply in order
+
+	shareMu sync.Mutex                // serializes share and its release
+	shared  map[string]*sharedChannel // channels subscribed through share, by name
+
+	mu            sync.RWMutex
+	client        *signalrClient // current connection
+	handlers      map[string]StreamHandler
+	authenticated bool
+	lastMessage   time.Time
+	err           error
+	done          chan struct{}
+}
+
+// TickerChannel returns the name of the ticker channel of market.
+func TickerChannel(market string) string {
+	return "ticker_" + normalizeSymbol(market)
+}
+
+// TradeChannel returns the name of the trade channel of market.
+func TradeChannel(market string) string {
+	return "trade_" + normalizeSymbol(market)
+}
+
+// OrderBookChannel returns the name of the order book channel of market at depth (1, 25 or 500).
+func OrderBookChannel(market string, depth int) string {
+	return fmt.Sprintf("orderbook_%s_%d", normalizeSymbol(market), depth)
+}
+
+// MarketSummaryChannel returns the name of the market summary channel of market.
+func MarketSummaryChannel(market string) string {
+	return "market_summary_" + normalizeSymbol(market)
+}
+
+// OpenStream connects to the websocket hub. The stream has no subscription until Subscribe is called.
+func (b *Bittrex) OpenStream(ctx context.Context, opts ...StreamOption) (*Stream, error) {
+	s := &Stream{
+		b:        b,
+		handlers: make(map[string]StreamHandler),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	client, err := s.connect(ctx)
+	if err != nil {
+		s.cancel()
+		return nil, err
+	}
+	if s.heartbeatTimeout > 0 {
+		if err = s.subscribe(ctx, "Subscribe", HEARTBEAT); err != nil {
+			s.cancel()
+			client.Close()
+			return nil, err
+		}
+	}
+
+	go s.supervise(client)
+	return s, nil
+}
+
+// connect opens a new connection and makes it the current one.
+func (s *Stream) connect(ctx context.Context) (*signalrClient, error) {
+	const timeout = 15 * time.Second
+
+	client := s.b.client.newSignalrClient()
+	client.OnClientMethod = s.dispatch
+	client.OnMessageError = func(err error) {
+		s.b.client.logger.Printf("ERROR OCCURRED: %s", err.Error())
+	}
+
+	err := doAsyncTimeout(ctx,
+		func() error {
+			return client.Connect("https", s.b.client.wsHost, []string{s.b.client.wsHub})
+		}, func(err error) {
+			if err == nil {
+				client.Close()
+			}
+		}, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.client = client
+	s.authenticated = false
+	s.lastMessage = s.b.client.clock.Now()
+	s.mu.Unlock()
+	return client, nil
+}
+
+// supervise watches the connection, and replaces it when lost if reconnection is enabled.
+func (s *Stream) supervise(client *signalrClient) {
+	for {
+		err := s.watch(client)
+		client.Close()
+		<-client.DisconnectedChannel
+		if err == ERR_STREAM_CLOSED || s.reconnect == nil {
+			s.finish(err)
+			return
+		}
+
+		s.b.client.logger.Printf("stream: %s, reconnecting", err)
+		if client, err = s.restore(); err != nil {
+			s.finish(err)
+			return
+		}
+	}
+}
+
+// watch blocks until client disconnects, misses heartbeats or the stream is closed.
+func (s *Stream) watch(client *signalrClient) error {
+	var heartbeats <-chan time.Time
+	if s.heartbeatTimeout > 0 {
+		tick := time.NewTicker(s.heartbeatTimeout / 4)
+		defer tick.Stop()
+		heartbeats = tick.C
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return ERR_STREAM_CLOSED
+		case <-client.DisconnectedChannel:
+			if s.ctx.Err() != nil {
+				return ERR_STREAM_CLOSED
+			}
+			return ERR_STREAM_DISCONNECTED
+		case <-heartbeats:
+			s.mu.RLock()
+			last := s.lastMessage
+			s.mu.RUnlock()
+			if s.b.client.clock.Now().Sub(last) > s.heartbeatTimeout {
+				return ERR_STREAM_HEARTBEAT_TIMEOUT
+			}
+		}
+	}
+}
+
+// restore reconnects, authenticates if needed and subscribes again to every channel,
+// then signals the gap to the channel handlers.
+func (s *Stream) restore() (*signalrClient, error) {
+	for attempt := 1; ; attempt++ {
+		client, err := s.connect(s.ctx)
+		if err == nil {
+			var channels []string
+			if channels, err = s.resubscribe(s.ctx); err == nil {
+				for _, channel := range channels {
+					s.route(channel, STREAM_GAP, nil)
+					if s.onGap != nil {
+						s.onGap(channel)
+					}
+				}
+				return client, nil
+			}
+			client.Close()
+			<-client.DisconnectedChannel
+		}
+
+		if s.ctx.Err() != nil {
+			return nil, ERR_STREAM_CLOSED
+		}
+		if s.reconnect.MaxAttempts > 0 && attempt >= s.reconnect.MaxAttempts {
+			return nil, err
+		}
+		s.b.client.logger.Printf("stream: reconnection attempt %d failed: %s", attempt, err)
+		if sleepCtx(s.ctx, s.b.client.clock, s.reconnect.backoff(attempt)) != nil {
+			return nil, ERR_STREAM_CLOSED
+		}
+	}
+}
+
+// resubscribe subscribes the current connection to the channels having a handler, which it returns.
+func (s *Stream) resubscribe(ctx context.Context) ([]string, error) {
+	channels := s.Channels()
+	subscriptions := channels
+	private := false
+	for _, channel := range channels {
+		private = private || isPrivateChannel(channel)
+	}
+	if s.heartbeatTimeout > 0 && !s.hasHandler(HEARTBEAT) {
+		subscriptions = append([]string{HEARTBEAT}, channels...)
+	}
+	if private {
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(subscriptions) > 0 {
+		if err := s.subscribe(ctx, "Subscribe", subscriptions...); err != nil {
+			return nil, err
+		}
+	}
+	return channels, nil
+}
+
+func (s *Stream) finish(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	s.cancel()
+	close(s.done)
+}
+
+// Done is closed once the stream is closed, or once the connection is lost
+// and cannot be restored.
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns why Done was closed: ERR_STREAM_CLOSED, ERR_STREAM_DISCONNECTED,
+// ERR_STREAM_HEARTBEAT_TIMEOUT or the error of the last reconnection attempt. It returns nil before.
+func (s *Stream) Err() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.err
+}
+
+// Close closes the connection and stops reconnecting.
+func (s *Stream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// Channels returns the subscribed channels, sorted.
+func (s *Stream) Channels() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	channels := make([]string, 0, len(s.handlers))
+	for channel := range s.handlers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+func (s *Stream) hasHandler(channel string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.handlers[channel]
+	return ok
+}
+
+// Subscribe subscribes to channel and routes its messages to handler. Subscribing again to
+// a channel replaces its handler. Private channels (order, balance, execution,
+// conditional_order) authenticate the connection first.
+func (s *Stream) Subscribe(ctx context.Context, channel string, handler StreamHandler) error {
+	if isPrivateChannel(channel) {
+		s.mu.RLock()
+		authenticated := s.authenticated
+		s.mu.RUnlock()
+		if !authenticated {
+			if err := s.authenticate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Register first, so the messages following the subscription are not lost
+	s.mu.Lock()
+	previous, resubscribe := s.handlers[channel]
+	s.handlers[channel] = handler
+	s.mu.Unlock()
+
+	err := s.subscribe(ctx, "Subscribe", channel)
+	if err != nil {
+		s.mu.Lock()
+		if resubscribe {
+			s.handlers[channel] = previous
+		} else {
+			delete(s.handlers, channel)
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// sharedChannel fans the messages of a channel out to the handlers sharing its subscription.
+type sharedChannel struct {
+	handlers map[int]StreamHandler
+	nextID   int
+}
+
+// share adds handler to channel, subscribing to it for the first handler only. release
+// removes handler, and unsubscribes once no handler is left, so consumers of a channel
+// (local order books of the same market) come and go without cutting each other off.
+func (s *Stream) share(ctx context.Context, channel string, handler StreamHandler) (release func() error, err error) {
+	s.shareMu.Lock()
+	defer s.shareMu.Unlock()
+	c, ok := s.shared[channel]
+	if !ok {
+		c = &sharedChannel{handlers: make(map[int]StreamHandler)}
+		fanOut := func(method string, payload json.RawMessage) {
+			s.mu.RLock()
+			handlers := make([]StreamHandler, 0, len(c.handlers))
+			for id := 0; id < c.nextID; id++ {
+				if h, ok := c.handlers[id]; ok {
+					handlers = append(handlers, h)
+				}
+			}
+			s.mu.RUnlock()
+			for _, h := range handlers {
+				h(method, payload)
+			}
+		}
+		if err = s.Subscribe(ctx, channel, fanOut); err != nil {
+			return nil, err
+		}
+		if s.shared == nil {
+			s.shared = make(map[string]*sharedChannel)
+		}
+		s.shared[channel] = c
+	}
+
+	s.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	c.handlers[id] = handler
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() error {
+		var err error
+		once.Do(func() { err = s.unshare(channel, c, id) })
+		return err
+	}, nil
+}
+
+func (s *Stream) unshare(channel string, c *sharedChannel, id int) error {
+	s.shareMu.Lock()
+	defer s.shareMu.Unlock()
+	s.mu.Lock()
+	delete(c.handlers, id)
+	last := len(c.handlers) == 0
+	s.mu.Unlock()
+	if !last || s.shared[channel] != c {
+		return nil
+	}
+	delete(s.shared, channel)
+	return s.Unsubscribe(context.Background(), channel)
+}
+
+// Unsubscribe unsubscribes from channels and drops their handlers.
+func (s *Stream) Unsubscribe(ctx context.Context, channels ...string) error {
+	var unsubscribe []string
+	s.mu.Lock()
+	for _, channel := range channels {
+		delete(s.handlers, channel)
+		// The heartbeat watchdog keeps its subscription
+		if channel != HEARTBEAT || s.heartbeatTimeout <= 0 {
+			unsubscribe = append(unsubscribe, channel)
+		}
+	}
+	s.mu.Unlock()
+	if len(unsubscribe) == 0 {
+		return nil
+	}
+	return s.subscribe(ctx, "Unsubscribe", unsubscribe...)
+}
+
+// subscribe calls the Subscribe or Unsubscribe hub method and checks the result of every channel.
+func (s *Stream) subscribe(ctx context.Context, method string, channels ...string) error {
+	resp, err := s.call(ctx, method, channels)
+	if err != nil {
+		return err
+	}
+	var results []Responce
+	if err = json.Unmarshal(resp, &results); err != nil {
+		return err
+	}
+	for i, r := range results {
+		if !r.Success && i < len(channels) {
+			return fmt.Errorf("%s %s: %v", method, channels[i], r.ErrorCode)
+		}
+	}
+	return nil
+}
+
+// authenticate signs in the connection, which private channels require.
+func (s *Stream) authenticate(ctx context.Context) error {
+	apiTimestamp, UUID, sig := s.b.client.wsSignature()
+	resp, err := s.call(ctx, "Authenticate", s.b.client.apiKey, apiTimestamp, UUID, sig)
+	if err != nil {
+		return err
+	}
+	r := &Responce{}
+	_ = json.Unmarshal(resp, r)
+	if !r.Success {
+		return fmt.Errorf("%s", r.ErrorCode)
+	}
+
+	s.mu.Lock()
+	s.authenticated = true
+	s.mu.Unlock()
+	return nil
+}
+
+// call invokes a hub method on the current connection, one at a time. It returns early with
+// ctx.Err() once ctx is done; the pending call gives up then too, or after hubCallTimeout.
+func (s *Stream) call(ctx context.Context, method string, args ...interface{}) (json.RawMessage, error) {
+	type result struct {
+		resp json.RawMessage
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		s.callMu.Lock()
+		defer s.callMu.Unlock()
+		s.mu.RLock()
+		client := s.client
+		s.mu.RUnlock()
+		select {
+		case <-client.DisconnectedChannel:
+			results <- result{err: ERR_STREAM_DISCONNECTED}
+			return
+		default:
+		}
+		resp, err := client.CallHubCtx(ctx, s.b.client.wsHub, method, args...)
+		results <- result{resp, err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch decodes the messages of the hub and routes them to the handler of their channel.
+func (s *Stream) dispatch(hub string, method string, messages []json.RawMessage) {
+	if hub != s.b.client.wsHub {
+		return
+	}
+
+	s.mu.Lock()
+	s.lastMessage = s.b.client.clock.Now()
+	s.mu.Unlock()
+
+	switch method {
+	case HEARTBEAT:
+		s.route(HEARTBEAT, method, nil)
+		return
+	case AUTHEXPIRED:
+		s.mu.Lock()
+		s.authenticated = false
+		s.mu.Unlock()
+		// Hub calls are answered by this goroutine, authenticate from another one
+		go func() {
+			ctx, cancel := context.WithTimeout(s.ctx, 15*time.Second)
+			defer cancel()
+			if err := s.authenticate(ctx); err != nil {
+				s.b.client.logger.Printf("authentication error: %s", err)
+			}
+		}()
+		return
+	}
+
+	for _, msg := range messages {
+		payload, err := decodeStreamMessage(msg)
+		if err != nil {
+			s.b.client.logger.Printf("decode error: %s %s", err.Error(), string(msg))
+			continue
+		}
+		if s.b.client.debug {
+			s.b.client.logger.Printf("%s %s", method, payload)
+		}
+
+		channel, err := streamChannel(method, payload)
+		if err != nil {
+			s.b.client.logger.Printf("unsupported message type: %s", method)
+			continue
+		}
+		s.route(channel, method, payload)
+	}
+}
+
+func (s *Stream) route(channel, method string, payload json.RawMessage) {
+	s.mu.RLock()
+	handler := s.handlers[channel]
+	s.mu.RUnlock()
+	if handler != nil {
+		handler(method, payload)
+	}
+}
+
+// streamChannel returns the channel a message was published on.
+func streamChannel(method string, payload []byte) (string, error) {
+	var m struct {
+		Symbol       string `json:"symbol"`
+		MarketSymbol string `json:"marketSymbol"`
+		Depth        int    `json:"depth"`
+	}
+
+	switch method {
+	case ORDER, BALANCE, EXECUTION:
+		return method, nil
+	case CONDITIONAL_ORDER:
+		return "conditional_order", nil
+	case MARKET_SUMMARIES:
+		return "market_summaries", nil
+	case TICKERS:
+		return "tickers", nil
+	case TICKER, MARKET_SUMMARY:
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return "", err
+		}
+		if method == TICKER {
+			return TickerChannel(m.Symbol), nil
+		}
+		return MarketSummaryChannel(m.Symbol), nil
+	case TRADE:
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return "", err
+		}
+		return TradeChannel(m.MarketSymbol), nil
+	case ORDERBOOK:
+		if err := json.Unmarshal(payload, &m); err != nil {
+			return "", err
+		}
+		return OrderBookChannel(m.MarketSymbol, m.Depth), nil
+	}
+	return "", fmt.Errorf("unsupported message type: %s", method)
+}
+
+// isPrivateChannel reports whether channel requires an authenticated connection.
+func isPrivateChannel(channel string) bool {
+	switch channel {
+	case ORDER, BALANCE, EXECUTION, "conditional_order":
+		return true
+	}
+	return false
+}
+
+// decodeStreamMessage decodes a websocket payload: a base64 string of raw deflate data.
+func decodeStreamMessage(msg json.RawMessage) (json.RawMessage, error) {
+	var s string
+	if err := json.Unmarshal(msg, &s); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// SubscribeHeartbeat calls f on every heartbeat of the hub.
+func (s *Stream) SubscribeHeartbeat(ctx context.Context, f func()) error {
+	return s.Subscribe(ctx, HEARTBEAT, func(method string, _ json.RawMessage) {
+		if method == HEARTBEAT {
+			f()
+		}
+	})
+}
+
+// SubscribeTicker calls f on every ticker update of market.
+func (s *Stream) SubscribeTicker(ctx context.Context, market string, f func(TickerV3)) error {
+	market, err := s.b.cachedMarketSymbol(market)
+	if err != nil {
+		return err
+	}
+	return s.Subscribe(ctx, TickerChannel(market), func(method string, payload json.RawMessage) {
+		var t TickerV3
+		if s.unmarshal(method, payload, &t) {
+			f(t)
+		}
+	})
+}
+
+// SubscribeTrades calls f on every trade update of market.
+func (s *Stream) SubscribeTrades(ctx context.Context, market string, f func(TradeUpdate)) error {
+	market, err := s.b.cachedMarketSymbol(market)
+	if err != nil {
+		return err
+	}
+	return s.Subscribe(ctx, TradeChannel(market), func(method string, payload json.RawMessage) {
+		var u TradeUpdate
+		if s.unmarshal(method, payload, &u) {
+			f(u)
+		}
+	})
+}
+
+// SubscribeOrderBook calls f on every order book delta of market at depth (1, 25 or 500).
+func (s *Stream) SubscribeOrderBook(ctx context.Context, market string, depth int, f func(OrderbookUpdate)) error {
+	market, err := s.b.cachedMarketSymbol(market)
+	if err != nil {
+		return err
+	}
+	return s.Subscribe(ctx, OrderBookChannel(market, depth), func(method string, payload json.RawMessage) {
+		var u OrderbookUpdate
+		if s.unmarshal(method, payload, &u) {
+			f(u)
+		}
+	})
+}
+
+// SubscribeOrders calls f on every update of the account orders.
+func (s *Stream) SubscribeOrders(ctx context.Context, f func(OrderUpdate)) error {
+	return s.Subscribe(ctx, ORDER, func(method string, payload json.RawMessage) {
+		var u OrderUpdate
+		if s.unmarshal(method, payload, &u) {
+			f(u)
+		}
+	})
+}
+
+// SubscribeBalances calls f on every update of the account balances.
+func (s *Stream) SubscribeBalances(ctx context.Context, f func(BalanceUpdate)) error {
+	return s.Subscribe(ctx, BALANCE, func(method string, payload json.RawMessage) {
+		var u BalanceUpdate
+		if s.unmarshal(method, payload, &u) {
+			f(u)
+		}
+	})
+}
+
+// unmarshal decodes payload into v. It returns false for STREAM_GAP: typed handlers
+// learn about gaps through WithGapHandler.
+func (s *Stream) unmarshal(method string, payload json.RawMessage, v interface{}) bool {
+	if method == STREAM_GAP {
+		return false
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		s.b.client.logger.Printf("%s Unmarshal err: %s", method, err.Error())
+		return false
+	}
+	return true
+}
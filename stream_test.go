@@ -0,0 +1,207 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func newTestStream(t *testing.T) (*bittrextest.Hub, *Stream) {
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+	b := New("key", "secret", WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()))
+	s, err := b.OpenStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return hub, s
+}
+
+func TestStreamMultiplexing(t *testing.T) {
+	hub, s := newTestStream(t)
+	ctx := context.Background()
+
+	tickers := make(chan TickerV3, 10)
+	books := make(chan OrderbookUpdate, 10)
+	assert.Nil(t, s.SubscribeTicker(ctx, "LTC-BTC", func(t TickerV3) { tickers <- t }))
+	assert.Nil(t, s.SubscribeTicker(ctx, "ETH-BTC", func(t TickerV3) { tickers <- t }))
+	assert.Nil(t, s.SubscribeOrderBook(ctx, "LTC-BTC", 25, func(u OrderbookUpdate) { books <- u }))
+	assert.Equal(t, []string{"orderbook_LTC-BTC_25", "ticker_ETH-BTC", "ticker_LTC-BTC"}, s.Channels())
+	assert.Equal(t, 1, hub.Connections())
+
+	assert.Nil(t, hub.PushTicker(bittrextest.Ticker{Symbol: "ETH-BTC", BidRate: decimal.RequireFromString("0.03")}))
+	select {
+	case tk := <-tickers:
+		assert.Equal(t, "ETH-BTC", tk.Symbol)
+		assert.True(t, decimal.RequireFromString("0.03").Equal(tk.BidRate))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ticker received")
+	}
+
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{
+		MarketSymbol: "LTC-BTC",
+		Depth:        25,
+		Sequence:     7,
+		BidDeltas:    []bittrextest.OrderBookEntry{{Quantity: decimal.NewFromInt(1), Rate: decimal.RequireFromString("0.004")}},
+	}))
+	select {
+	case u := <-books:
+		assert.Equal(t, "LTC-BTC", u.MarketSymbol)
+		assert.Equal(t, 7, u.Sequence)
+		assert.Len(t, u.BidDeltas, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order book received")
+	}
+
+	// Removed channels are no longer routed
+	assert.Nil(t, s.Unsubscribe(ctx, TickerChannel("LTC-BTC")))
+	assert.Nil(t, hub.PushTicker(bittrextest.Ticker{Symbol: "LTC-BTC"}))
+	assert.Nil(t, hub.PushTicker(bittrextest.Ticker{Symbol: "ETH-BTC"}))
+	select {
+	case tk := <-tickers:
+		assert.Equal(t, "ETH-BTC", tk.Symbol)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ticker received")
+	}
+	assert.Equal(t, []string{"orderbook_LTC-BTC_25", "ticker_ETH-BTC"}, s.Channels())
+}
+
+func TestStreamPrivateChannels(t *testing.T) {
+	hub, s := newTestStream(t)
+	ctx := context.Background()
+
+	orders := make(chan OrderUpdate, 1)
+	assert.Nil(t, s.SubscribeOrders(ctx, func(u OrderUpdate) { orders <- u }))
+	calls := hub.Calls()
+	if assert.Len(t, calls, 2) {
+		assert.Equal(t, "Authenticate", calls[0].Method)
+	}
+
+	assert.Nil(t, hub.PushOrder(bittrextest.OrderUpdate{
+		Sequence: 3,
+		Delta:    bittrextest.Order{ID: "order-id", MarketSymbol: "LTC-BTC", Status: "OPEN", CreatedAt: time.Now()},
+	}))
+	select {
+	case u := <-orders:
+		assert.Equal(t, 3, u.Sequence)
+		assert.Equal(t, "order-id", u.Delta.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order received")
+	}
+
+	// The stream authenticates again when the hub asks to
+	hub.ExpireAuth()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(hub.Calls()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "Authenticate", hub.Calls()[2].Method)
+}
+
+func TestStreamSubscribeFailure(t *testing.T) {
+	_, s := newTestStream(t)
+	s.b.client.apiSecret = "wrong secret"
+
+	err := s.Subscribe(context.Background(), BALANCE, func(string, json.RawMessage) {})
+	assert.EqualError(t, err, CODE_INVALID_SIGNATURE)
+	assert.Empty(t, s.Channels())
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	hub, s := newTestStream(t)
+
+	hub.Disconnect()
+	select {
+	case <-s.Done():
+		assert.Equal(t, ERR_STREAM_DISCONNECTED, s.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnection not detected")
+	}
+	assert.Equal(t, ERR_STREAM_DISCONNECTED, s.Subscribe(context.Background(), HEARTBEAT, nil))
+}
+
+func TestStreamReconnect(t *testing.T) {
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+	b := New("key", "secret", WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()))
+	gaps := make(chan string, 10)
+	s, err := b.OpenStream(context.Background(),
+		WithReconnect(RetryPolicy{InitialBackoff: 10 * time.Millisecond}),
+		WithGapHandler(func(channel string) { gaps <- channel }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	events := make(chan string, 10)
+	handler := func(method string, payload json.RawMessage) { events <- method }
+	assert.Nil(t, s.Subscribe(ctx, TickerChannel("LTC-BTC"), handler))
+	assert.Nil(t, s.Subscribe(ctx, BALANCE, handler))
+
+	hub.Disconnect()
+	for _, channel := range []string{"balance", "ticker_LTC-BTC"} {
+		select {
+		case gap := <-gaps:
+			assert.Equal(t, channel, gap)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no gap signalled")
+		}
+	}
+	assert.Equal(t, []string{STREAM_GAP, STREAM_GAP}, []string{<-events, <-events})
+
+	// The new connection is authenticated and subscribed
+	assert.Equal(t, 1, hub.Connections())
+	calls := hub.Calls()
+	if assert.Len(t, calls, 5) {
+		assert.Equal(t, "Authenticate", calls[3].Method)
+		assert.Equal(t, `["balance","ticker_LTC-BTC"]`, string(calls[4].Arguments[0]))
+	}
+	assert.Nil(t, hub.PushBalance(bittrextest.BalanceUpdate{Sequence: 1}))
+	select {
+	case method := <-events:
+		assert.Equal(t, BALANCE, method)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no balance received")
+	}
+	assert.Nil(t, s.Err())
+}
+
+func TestStreamReconnectGiveUp(t *testing.T) {
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+	b := New("key", "secret", WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()))
+	s, err := b.OpenStream(context.Background(),
+		WithReconnect(RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Millisecond}),
+		WithHeartbeatTimeout(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	assert.Nil(t, hub.WaitSubscribed(HEARTBEAT, time.Second))
+
+	// Heartbeats keep the connection alive
+	for i := 0; i < 5; i++ {
+		hub.Heartbeat()
+		time.Sleep(50 * time.Millisecond)
+	}
+	assert.Len(t, hub.Calls(), 1)
+
+	// Missed heartbeats drop it, then reconnections fail
+	hub.Close()
+	select {
+	case <-s.Done():
+		assert.Error(t, s.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconnection did not give up")
+	}
+}
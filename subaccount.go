@@ -0,0 +1,53 @@
+package bittrex
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SubaccountV3 is a subaccount of the master account.
+type SubaccountV3 struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TransferParams describes a transfer to a subaccount (ToSubaccountID) or to the master
+// account (ToMasterAccount), one of them.
+type TransferParams struct {
+	ToSubaccountID  string          `json:"toSubaccountId,omitempty"`
+	ToMasterAccount bool            `json:"toMasterAccount,omitempty"`
+	RequestID       string          `json:"requestId"`
+	CurrencySymbol  string          `json:"currencySymbol"`
+	Amount          decimal.Decimal `json:"amount"`
+}
+
+// TransferV3 is a transfer between the master account and its subaccounts. The To fields are
+// set on sent transfers, the From fields on received ones.
+type TransferV3 struct {
+	ID                string          `json:"id"`
+	RequestID         string          `json:"requestId"`
+	CurrencySymbol    string          `json:"currencySymbol"`
+	Amount            decimal.Decimal `json:"amount"`
+	ExecutedAt        time.Time       `json:"executedAt"`
+	ToSubaccountID    string          `json:"toSubaccountId"`
+	ToMasterAccount   bool            `json:"toMasterAccount"`
+	FromSubaccountID  string          `json:"fromSubaccountId"`
+	FromMasterAccount bool            `json:"fromMasterAccount"`
+}
+
+// SentTransfersParams filters the sent transfers.
+type SentTransfersParams struct {
+	ToSubaccountID  string `url:"toSubaccountId,omitempty"`
+	ToMasterAccount bool   `url:"toMasterAccount,omitempty"`
+	CurrencySymbol  string `url:"currencySymbol,omitempty"`
+	PageParams
+}
+
+// ReceivedTransfersParams filters the received transfers.
+type ReceivedTransfersParams struct {
+	FromSubaccountID  string `url:"fromSubaccountId,omitempty"`
+	FromMasterAccount bool   `url:"fromMasterAccount,omitempty"`
+	CurrencySymbol    string `url:"currencySymbol,omitempty"`
+	PageParams
+}
@@ -0,0 +1,93 @@
+package bittrex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func TestGetSubaccountsPages(t *testing.T) {
+	srv := bittrextest.NewServer("key", "secret")
+	defer srv.Close()
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry))
+	pages := func() int {
+		n := 0
+		for _, r := range srv.Requests() {
+			if r.Method == "GET" && r.URL.Path == "/v3/subaccounts" {
+				n++
+			}
+		}
+		return n
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := b.CreateSubaccount()
+		assert.Nil(t, err)
+	}
+	subaccounts, err := b.GetSubaccounts()
+	assert.Nil(t, err)
+	assert.Len(t, subaccounts, 3)
+	assert.Equal(t, 1, pages())
+
+	// A full page is followed by the next one
+	for i := 0; i < 200; i++ {
+		_, err = b.CreateSubaccount()
+		assert.Nil(t, err)
+	}
+	subaccounts, err = b.GetSubaccounts()
+	assert.Nil(t, err)
+	assert.Len(t, subaccounts, 203)
+	assert.Equal(t, 3, pages())
+}
+
+func TestSubaccounts(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+
+	sub, err := b.CreateSubaccount()
+	assert.Nil(t, err)
+	subaccounts, err := b.GetSubaccounts()
+	assert.Nil(t, err)
+	if assert.Len(t, subaccounts, 1) {
+		assert.Equal(t, sub.ID, subaccounts[0].ID)
+	}
+
+	transfer, err := b.Transfer(TransferParams{ToSubaccountID: sub.ID, CurrencySymbol: "BTC", Amount: d("0.4")})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, transfer.RequestID)
+	assert.True(t, d("0.6").Equal(srv.Balance("BTC").Total))
+
+	subBt := b.WithSubaccount(sub.ID)
+	assert.Equal(t, sub.ID, subBt.SubaccountID())
+	balances, err := subBt.GetBalances()
+	assert.Nil(t, err)
+	if assert.Len(t, balances, 1) {
+		assert.True(t, d("0.4").Equal(balances[0].Total))
+	}
+	received, err := subBt.GetReceivedTransfers(ReceivedTransfersParams{FromMasterAccount: true})
+	assert.Nil(t, err)
+	if assert.Len(t, received, 1) {
+		assert.Equal(t, transfer.ID, received[0].ID)
+		assert.True(t, received[0].FromMasterAccount)
+	}
+
+	_, err = subBt.Transfer(TransferParams{ToMasterAccount: true, CurrencySymbol: "BTC", Amount: d("0.1")})
+	assert.Nil(t, err)
+	assert.True(t, d("0.7").Equal(srv.Balance("BTC").Total))
+	sent, err := b.GetSentTransfers(SentTransfersParams{ToSubaccountID: sub.ID})
+	assert.Nil(t, err)
+	assert.Len(t, sent, 1)
+	received, err = b.GetReceivedTransfers(ReceivedTransfersParams{FromSubaccountID: sub.ID})
+	assert.Nil(t, err)
+	assert.Len(t, received, 1)
+
+	_, err = subBt.Transfer(TransferParams{ToMasterAccount: true, CurrencySymbol: "BTC", Amount: d("1")})
+	assert.NotNil(t, err)
+	_, err = b.Transfer(TransferParams{CurrencySymbol: "BTC", Amount: d("0.1")})
+	assert.True(t, errors.Is(err, ERR_TRANSFER_MISSING_PARAMETERS))
+	_, err = b.WithSubaccount("unknown").GetBalances()
+	assert.NotNil(t, err)
+}
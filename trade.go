@@ -22,4 +22,11 @@ type TradeV3 struct {
 	Quantity   string `json:"quantity"`
 	Rate       string `json:"rate"`
 	TakerSide  string `json:"takerSide"`
-}
\ No newline at end of file
+}
+
+//TradeUpdate struct
+type TradeUpdate struct {
+	Sequence     int       `json:"sequence"`
+	MarketSymbol string    `json:"marketSymbol"`
+	Deltas       []TradeV3 `json:"deltas"`
+}
@@ -0,0 +1,196 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// MARKET_ONLINE is the status of a market accepting orders
+const MARKET_ONLINE = "ONLINE"
+
+// Reasons of a ValidationError, matched through errors.Is
+var (
+	ERR_UNKNOWN_MARKET            = errors.New("unknown market")
+	ERR_PRECISION                 = errors.New("too many decimals")
+	ERR_BELOW_MIN_TRADE_SIZE      = errors.New("quantity below minimum trade size")
+	ERR_BELOW_MIN_NOTIONAL        = errors.New("order value below minimum")
+	ERR_TIME_IN_FORCE_NOT_ALLOWED = errors.New("time in force not allowed for order type")
+)
+
+// DefaultMinNotional is the minimum value of an order per quote currency, below which Bittrex
+// rejects it as dust (DUST_TRADE_DISALLOWED_MIN_VALUE).
+var DefaultMinNotional = map[string]decimal.Decimal{
+	"BTC": decimal.RequireFromString("0.0005"),
+}
+
+// ValidationError is returned by OrderValidator for an order Bittrex would reject.
+// errors.Is matches Reason, ERR_ORDER_INVALID_PARAMETERS, and ERR_MARKET_OFFLINE when the
+// market is not online.
+type ValidationError struct {
+	Market string
+	Field  string // parameter at fault, e.g. quantity or limit
+	Reason error  // one of the ERR_ validation reasons, or ERR_MARKET_OFFLINE
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s %s: %s", e.Market, e.Field, e.Reason, e.Detail)
+}
+
+// Unwrap returns the reason of the error.
+func (e *ValidationError) Unwrap() error {
+	return e.Reason
+}
+
+// Is makes every ValidationError match ERR_ORDER_INVALID_PARAMETERS.
+func (e *ValidationError) Is(target error) bool {
+	return target == ERR_ORDER_INVALID_PARAMETERS
+}
+
+// RoundingMode tells an OrderValidator what to do with amounts finer than market precision.
+type RoundingMode int
+
+const (
+	// REJECT_IMPRECISE fails with ERR_PRECISION
+	REJECT_IMPRECISE RoundingMode = iota
+	// ROUND_SAFE rounds towards the cautious side: quantities and ceilings down, buy limits
+	// down and sell limits up
+	ROUND_SAFE
+)
+
+// OrderValidator checks orders against market metadata before they are sent: market status,
+// decimals of quantity and prices, minimum trade size and value, and time in force.
+// Market metadata comes from the reference data cache of the client.
+//
+// Attach it with Bittrex.SetOrderValidator to check every order created by CreateOrder and
+// its helpers, Batch and CreateConditionalOrder, or call Validate directly.
+type OrderValidator struct {
+	b *Bittrex
+
+	// Rounding tells what to do with amounts finer than market precision (default REJECT_IMPRECISE)
+	Rounding RoundingMode
+	// MinNotional is the minimum order value per quote currency (default DefaultMinNotional)
+	MinNotional map[string]decimal.Decimal
+}
+
+// NewOrderValidator returns a validator using the reference data of b.
+func (b *Bittrex) NewOrderValidator() *OrderValidator {
+	return &OrderValidator{b: b, MinNotional: DefaultMinNotional}
+}
+
+// SetOrderValidator makes CreateOrder and its helpers, Batch and CreateConditionalOrder
+// validate the orders they create with v first, which may round their amounts. A nil v
+// disables validation.
+func (b *Bittrex) SetOrderValidator(v *OrderValidator) {
+	b.client.validator = v
+}
+
+// Refresh reloads the markets of the reference data cache.
+func (v *OrderValidator) Refresh(ctx context.Context) error {
+	return v.b.ReferenceData().RefreshMarkets(ctx)
+}
+
+// market returns the metadata of symbol.
+func (v *OrderValidator) market(ctx context.Context, symbol string) (MarketV3, error) {
+	m, err := v.b.ReferenceData().Market(ctx, symbol)
+	if errors.Is(err, ERR_NOT_FOUND) {
+		return m, &ValidationError{Market: symbol, Field: "marketSymbol", Reason: ERR_UNKNOWN_MARKET, Detail: "not listed"}
+	}
+	return m, err
+}
+
+// Validate checks params, and returns them with their amounts rounded to market precision
+// if Rounding allows it. Errors are *ValidationError, ERR_INVALID_MARKET_SYMBOL, or those of
+// fetching the markets.
+func (v *OrderValidator) Validate(ctx context.Context, params CreateOrderParams) (CreateOrderParams, error) {
+	if params.Type == "" || params.MarketSymbol == "" || params.Direction == "" || params.TimeInForce == "" {
+		return params, ERR_ORDER_MISSING_PARAMETERS
+	}
+	symbol, err := v.b.marketSymbol(ctx, params.MarketSymbol)
+	if err != nil {
+		return params, err
+	}
+	params.MarketSymbol = symbol
+	m, err := v.market(ctx, params.MarketSymbol)
+	if err != nil {
+		return params, err
+	}
+	invalid := func(field string, reason error, format string, a ...interface{}) error {
+		return &ValidationError{Market: m.Symbol, Field: field, Reason: reason, Detail: fmt.Sprintf(format, a...)}
+	}
+
+	if m.Status != MARKET_ONLINE {
+		return params, invalid("marketSymbol", ERR_MARKET_OFFLINE, "status is %s", m.Status)
+	}
+	if !timeInForceAllowed(params.Type, params.TimeInForce) {
+		return params, invalid("timeInForce", ERR_TIME_IN_FORCE_NOT_ALLOWED, "%s orders do not accept %s", params.Type, params.TimeInForce)
+	}
+
+	if params.Type == MARKET || params.Type == LIMIT {
+		quantity, err := v.round(params.Quantity, m.Precision, false)
+		if err != nil {
+			return params, invalid("quantity", err, "%s has more than %d decimals", params.Quantity, m.Precision)
+		}
+		if quantity.LessThan(m.MinTradeSize) {
+			return params, invalid("quantity", ERR_BELOW_MIN_TRADE_SIZE, "%s is lower than %s", quantity, m.MinTradeSize)
+		}
+		params.Quantity = quantity
+	}
+	if params.Type == LIMIT || params.Type == CEILING_LIMIT {
+		limit, err := v.round(params.Limit, m.Precision, params.Direction == SELL)
+		if err != nil {
+			return params, invalid("limit", err, "%s has more than %d decimals", params.Limit, m.Precision)
+		}
+		if !limit.IsPositive() {
+			return params, invalid("limit", ERR_PRECISION, "%s is not positive at %d decimals", params.Limit, m.Precision)
+		}
+		params.Limit = limit
+	}
+	notional := params.Quantity.Mul(params.Limit)
+	if params.Type == CEILING_LIMIT || params.Type == CEILING_MARKET {
+		ceiling, err := v.round(params.Ceiling, m.Precision, false)
+		if err != nil {
+			return params, invalid("ceiling", err, "%s has more than %d decimals", params.Ceiling, m.Precision)
+		}
+		params.Ceiling = ceiling
+		notional = ceiling
+	}
+
+	// The value of MARKET orders is unknown until they fill
+	if min, ok := v.MinNotional[m.QuoteCurrencySymbol]; ok && params.Type != MARKET && notional.LessThan(min) {
+		return params, invalid("notional", ERR_BELOW_MIN_NOTIONAL, "%s %s is lower than %s", notional, m.QuoteCurrencySymbol, min)
+	}
+	return params, nil
+}
+
+// round returns amount with at most places decimals, rounded up if up is set, or
+// ERR_PRECISION if the validator rejects imprecise amounts.
+func (v *OrderValidator) round(amount decimal.Decimal, places int32, up bool) (decimal.Decimal, error) {
+	truncated := amount.Truncate(places)
+	if truncated.Equal(amount) {
+		return amount, nil
+	}
+	if v.Rounding == REJECT_IMPRECISE {
+		return amount, ERR_PRECISION
+	}
+	if up {
+		return truncated.Add(decimal.New(1, -places)), nil
+	}
+	return truncated, nil
+}
+
+// timeInForceAllowed reports whether Bittrex accepts timeInForce for orders of type orderType.
+func timeInForceAllowed(orderType OrderType, timeInForce TimeInForce) bool {
+	switch timeInForce {
+	case IMMEDIATE_OR_CANCEL, FILL_OR_KILL:
+		return true
+	case GOOD_TIL_CANCELLED, POST_ONLY_GOOD_TIL_CANCELLED:
+		return orderType == LIMIT
+	case BUY_NOW:
+		return orderType == CEILING_MARKET
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func newTestValidator(t *testing.T, precision int32) (*bittrextest.Server, *Bittrex, *OrderValidator) {
+	srv := bittrextest.NewServer("key", "secret")
+	t.Cleanup(srv.Close)
+	srv.AddMarket(bittrextest.Market{Symbol: "LTC-BTC", BaseCurrencySymbol: "LTC", QuoteCurrencySymbol: "BTC", MinTradeSize: d("0.01"), Precision: precision})
+	srv.SetBalance("BTC", d("1"))
+	b := New("key", "secret", WithBaseURL(srv.URL()), WithRetryPolicy(NoRetry))
+	v := b.NewOrderValidator()
+	b.SetOrderValidator(v)
+	return srv, b, v
+}
+
+func TestOrderValidatorCreatePaths(t *testing.T) {
+	srv, b, _ := newTestValidator(t, 8)
+	tooSmall := CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("0.001"), Limit: d("0.5"), TimeInForce: GOOD_TIL_CANCELLED}
+
+	_, err := b.Batch([]BatchOperation{BatchCancelOrder("order"), BatchCreateOrder(tooSmall)})
+	assert.True(t, errors.Is(err, ERR_BELOW_MIN_TRADE_SIZE), "%v", err)
+	_, err = b.CreateConditionalOrder(CreateConditionalOrderParams{
+		MarketSymbol:  "LTC-BTC",
+		Operand:       LTE,
+		TriggerPrice:  d("0.003"),
+		OrderToCreate: &tooSmall,
+	})
+	assert.True(t, errors.Is(err, ERR_BELOW_MIN_TRADE_SIZE), "%v", err)
+	for _, r := range srv.Requests() {
+		assert.NotEqual(t, "POST", r.Method)
+	}
+}
+
+func TestOrderValidatorMarketPrecision(t *testing.T) {
+	_, b, v := newTestValidator(t, 4)
+	ctx := context.Background()
+	params := CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("1.23456"), Limit: d("0.5"), TimeInForce: GOOD_TIL_CANCELLED}
+
+	// Quantities have the decimals of the market too
+	_, err := b.CreateOrder(params)
+	assert.True(t, errors.Is(err, ERR_PRECISION), "%v", err)
+	v.Rounding = ROUND_SAFE
+	params, err = v.Validate(ctx, params)
+	assert.Nil(t, err)
+	assert.True(t, d("1.2345").Equal(params.Quantity))
+}
+
+func TestOrderValidator(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.SetBalance("BTC", d("1"))
+	v := b.NewOrderValidator()
+	b.SetOrderValidator(v)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		params CreateOrderParams
+		reason error
+	}{
+		{CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("0.001"), Limit: d("0.5"), TimeInForce: GOOD_TIL_CANCELLED}, ERR_BELOW_MIN_TRADE_SIZE},
+		{CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("1"), Limit: d("0.0001"), TimeInForce: GOOD_TIL_CANCELLED}, ERR_BELOW_MIN_NOTIONAL},
+		{CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("1.123456789"), Limit: d("0.003"), TimeInForce: GOOD_TIL_CANCELLED}, ERR_PRECISION},
+		{CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: LIMIT, Quantity: d("1"), Limit: d("0.003123456789"), TimeInForce: GOOD_TIL_CANCELLED}, ERR_PRECISION},
+		{CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: BUY, Type: MARKET, Quantity: d("1"), TimeInForce: GOOD_TIL_CANCELLED}, ERR_TIME_IN_FORCE_NOT_ALLOWED},
+		{CreateOrderParams{MarketSymbol: "DOGE-BTC", Direction: BUY, Type: MARKET, Quantity: d("1"), TimeInForce: IMMEDIATE_OR_CANCEL}, ERR_UNKNOWN_MARKET},
+	} {
+		_, err := b.CreateOrder(tc.params)
+		assert.True(t, errors.Is(err, tc.reason), "%v", err)
+		assert.True(t, errors.Is(err, ERR_ORDER_INVALID_PARAMETERS))
+	}
+	for _, r := range srv.Requests() {
+		assert.NotEqual(t, "POST", r.Method)
+	}
+
+	v.Rounding = ROUND_SAFE
+	order, err := b.LimitBuy("LTC-BTC", d("1.123456789"), d("0.003123456789"), GOOD_TIL_CANCELLED)
+	assert.Nil(t, err)
+	assert.True(t, d("1.12345678").Equal(order.Quantity))
+	assert.True(t, d("0.00312345").Equal(order.Limit))
+	params, err := v.Validate(ctx, CreateOrderParams{MarketSymbol: "LTC-BTC", Direction: SELL, Type: LIMIT, Quantity: d("1"), Limit: d("0.003123456789"), TimeInForce: GOOD_TIL_CANCELLED})
+	assert.Nil(t, err)
+	assert.True(t, d("0.00312346").Equal(params.Limit))
+
+	// The snapshot is only refreshed once expired
+	srv.SetMarketStatus("LTC-BTC", "OFFLINE", "maintenance")
+	_, err = v.Validate(ctx, params)
+	assert.Nil(t, err)
+	assert.Nil(t, v.Refresh(ctx))
+	_, err = v.Validate(ctx, params)
+	assert.True(t, IsMarketOffline(err))
+}
+
+func TestOrderValidatorRound(t *testing.T) {
+	v := &OrderValidator{Rounding: ROUND_SAFE}
+	for _, tc := range []struct {
+		amount string
+		places int32
+		up     bool
+		want   string
+	}{
+		{"1.23456", 4, false, "1.2345"},
+		{"1.23451", 4, true, "1.2346"},
+		{"1.2345", 4, true, "1.2345"},
+		{"0.5", 0, true, "1"},
+		{"0.5", 0, false, "0"},
+		{"120", 0, true, "120"},
+	} {
+		rounded, err := v.round(d(tc.amount), tc.places, tc.up)
+		assert.Nil(t, err)
+		assert.True(t, d(tc.want).Equal(rounded), "%s at %d: %s", tc.amount, tc.places, rounded)
+	}
+
+	v.Rounding = REJECT_IMPRECISE
+	_, err := v.round(d("1.23456"), 4, false)
+	assert.True(t, errors.Is(err, ERR_PRECISION))
+	rounded, err := v.round(d("1.2345"), 4, true)
+	assert.Nil(t, err)
+	assert.True(t, d("1.2345").Equal(rounded))
+}
@@ -41,4 +41,5 @@ type WithdrawalV3 struct {
 type WithdrawalHistoryParams struct {
 	Status         string `url:"status,omitempty"`
 	CurrencySymbol string `url:"currencySymbol,omitempty"`
+	PageParams            // closed withdrawals only
 }
@@ -0,0 +1,46 @@
+package bittrex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
+)
+
+func TestWallet(t *testing.T) {
+	srv, b := newTestServer(t)
+	srv.AddDeposit(bittrextest.Deposit{CurrencySymbol: "LTC", Quantity: d("10"), TxID: "tx1"})
+	srv.AddDeposit(bittrextest.Deposit{CurrencySymbol: "LTC", Quantity: d("1"), Status: "PENDING"})
+
+	balances, err := b.GetBalances()
+	assert.Nil(t, err)
+	if assert.Len(t, balances, 1) {
+		assert.True(t, d("10").Equal(balances[0].Total))
+	}
+
+	deposits, err := b.GetClosedDepositHistory("LTC", DEPOSIT_ALL)
+	assert.Nil(t, err)
+	assert.Len(t, deposits, 1)
+	deposits, err = b.GetOpenDepositHistory("all", DEPOSIT_ALL)
+	assert.Nil(t, err)
+	assert.Len(t, deposits, 1)
+
+	address, err := b.GetDepositAddress("LTC")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, address.CryptoAddress)
+
+	withdrawal, err := b.Withdraw("ltc-address", "LTC", d("4"), "")
+	assert.Nil(t, err)
+	assert.Equal(t, REQUESTED, withdrawal.Status)
+	assert.True(t, d("6").Equal(srv.Balance("LTC").Total))
+
+	srv.CompleteWithdrawal(withdrawal.ID, "tx2")
+	byTx, err := b.GetWithdrawalByTxId("tx2")
+	assert.Nil(t, err)
+	assert.Equal(t, withdrawal.ID, byTx.ID)
+
+	closed, err := b.GetClosedWithdrawals("all", ALL)
+	assert.Nil(t, err)
+	assert.Len(t, closed, 1)
+}
@@ -3,6 +3,7 @@ package bittrex
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/base64"
@@ -19,16 +20,18 @@ import (
 	"github.com/thebotguys/signalr"
 )
 
-//Responce struct
+// Responce struct
 type Responce struct {
 	Success   bool        `json:"Success"`
 	ErrorCode interface{} `json:"ErrorCode"`
 }
 
 // doAsyncTimeout runs f in a different goroutine
+//
 //	if f returns before timeout elapses, doAsyncTimeout returns the result of f().
-//	otherwise it returns "operation timeout" error, and calls tmFunc after f returns.
-func doAsyncTimeout(f func() error, tmFunc func(error), timeout time.Duration) error {
+//	otherwise it returns "operation timeout" error (or ctx error if ctx is done first),
+//	and calls tmFunc after f returns.
+func doAsyncTimeout(ctx context.Context, f func() error, tmFunc func(error), timeout time.Duration) error {
 	errs := make(chan error)
 
 	go func() {
@@ -47,23 +50,50 @@ func doAsyncTimeout(f func() error, tmFunc func(error), timeout time.Duration) e
 		return err
 	case <-time.After(timeout):
 		return errors.New("operation timeout")
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-//Authentication func
-func (b *Bittrex) Authentication(c *signalr.Client) error {
-	r := &Responce{}
+// closeOnDone closes c as soon as ctx is done, which unblocks any pending hub call.
+// The returned func stops watching ctx.
+func closeOnDone(ctx context.Context, c *signalrClient) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
 
-	apiTimestamp := time.Now().UnixNano() / 1000000
-	UUID := uuid.New().String()
+// wsSignature returns the arguments of the Authenticate hub method, but the api key.
+func (c *client) wsSignature() (apiTimestamp int64, UUID string, sig string) {
+	apiTimestamp = c.clock.Now().UnixNano() / 1000000
+	UUID = uuid.New().String()
 
 	preSign := strings.Join([]string{fmt.Sprintf("%d", apiTimestamp), UUID}, "")
 
-	mac := hmac.New(sha512.New, []byte(b.client.apiSecret))
-	_, err := mac.Write([]byte(preSign))
-	sig := hex.EncodeToString(mac.Sum(nil))
+	mac := hmac.New(sha512.New, []byte(c.apiSecret))
+	mac.Write([]byte(preSign))
+	sig = hex.EncodeToString(mac.Sum(nil))
+	return
+}
+
+// Authentication authenticates a connection opened with the thebotguys/signalr client.
+func (b *Bittrex) Authentication(c *signalr.Client) error {
+	return b.authenticate(c)
+}
 
-	auth, err := c.CallHub(WS_HUB, "Authenticate", b.client.apiKey, apiTimestamp, UUID, sig)
+// authenticate calls the Authenticate hub method.
+func (b *Bittrex) authenticate(c hubCaller) error {
+	r := &Responce{}
+
+	apiTimestamp, UUID, sig := b.client.wsSignature()
+
+	auth, err := c.CallHub(b.client.wsHub, "Authenticate", b.client.apiKey, apiTimestamp, UUID, sig)
 	if err != nil {
 		return err
 	}
@@ -79,13 +109,23 @@ func (b *Bittrex) Authentication(c *signalr.Client) error {
 
 // SubscribeTickerUpdates subscribes for updates of the market.
 func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) error {
+	return b.SubscribeTickerUpdatesCtx(context.Background(), market, ticker)
+}
+
+// SubscribeTickerUpdatesCtx is the context-aware variant of SubscribeTickerUpdates.
+// It returns ctx.Err() once ctx is done.
+func (b *Bittrex) SubscribeTickerUpdatesCtx(ctx context.Context, market string, ticker chan<- Ticker) error {
 	const timeout = 5 * time.Second
-	client := signalr.NewWebsocketClient()
+	market, err := b.cachedMarketSymbol(market)
+	if err != nil {
+		return err
+	}
+	client := b.client.newSignalrClient()
 
 	var updTime int64
 
 	client.OnClientMethod = func(hub string, method string, messages []json.RawMessage) {
-		if hub != WS_HUB {
+		if hub != b.client.wsHub {
 			return
 		}
 
@@ -94,19 +134,19 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 			atomic.StoreInt64(&updTime, time.Now().Unix())
 
 		default:
-			fmt.Printf("unsupported message type: %s\n", method)
+			b.client.logger.Printf("unsupported message type: %s", method)
 		}
 
 		for _, msg := range messages {
 			dbuf, err := base64.StdEncoding.DecodeString(strings.Trim(string(msg), `"`))
 			if err != nil {
-				fmt.Printf("DecodeString error: %s %s\n", err.Error(), string(msg))
+				b.client.logger.Printf("DecodeString error: %s %s", err.Error(), string(msg))
 				continue
 			}
 
 			r, err := zlib.NewReader(bytes.NewReader(append([]byte{120, 156}, dbuf...)))
 			if err != nil {
-				fmt.Printf("unzip error %s %s \n", err.Error(), string(msg))
+				b.client.logger.Printf("unzip error %s %s", err.Error(), string(msg))
 				continue
 			}
 			defer r.Close()
@@ -114,7 +154,9 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 			var out bytes.Buffer
 			io.Copy(&out, r)
 
-			fmt.Println(out.String())
+			if b.client.debug {
+				b.client.logger.Printf("%s", out.String())
+			}
 
 			p := Ticker{}
 			json.Unmarshal([]byte(out.String()), &p)
@@ -122,18 +164,18 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 			select {
 			case ticker <- p:
 			default:
-				fmt.Printf("ticker send err: %s %d \n", market, len(ticker))
+				b.client.logger.Printf("ticker send err: %s %d", market, len(ticker))
 			}
 		}
 	}
 
 	client.OnMessageError = func(err error) {
-		fmt.Printf("ERROR OCCURRED: %s\n", err.Error())
+		b.client.logger.Printf("ERROR OCCURRED: %s", err.Error())
 	}
 
-	err := doAsyncTimeout(
+	err = doAsyncTimeout(ctx,
 		func() error {
-			return client.Connect("https", WS_BASE, []string{WS_HUB})
+			return client.Connect("https", b.client.wsHost, []string{b.client.wsHub})
 		}, func(err error) {
 			if err == nil {
 				client.Close()
@@ -144,8 +186,9 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 	}
 
 	defer client.Close()
+	defer closeOnDone(ctx, client)()
 
-	_, err = client.CallHub(WS_HUB, "Subscribe", []interface{}{"heartbeat", "ticker_" + market, "trade_" + market})
+	_, err = client.CallHub(b.client.wsHub, "Subscribe", []interface{}{"heartbeat", TickerChannel(market), TradeChannel(market)})
 	if err != nil {
 		return err
 	}
@@ -155,7 +198,13 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 	for {
 		select {
 		case <-client.DisconnectedChannel:
+			// closeOnDone closes the client too, report the cancellation
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return errors.New("client.DisconnectedChannel")
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-tick.C:
 			if time.Now().Unix()-atomic.LoadInt64(&updTime) > 60 {
 				return errors.New("ticker messages timeout")
@@ -166,8 +215,14 @@ func (b *Bittrex) SubscribeTickerUpdates(market string, ticker chan<- Ticker) er
 
 // SubscribeOrderUpdates func
 func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
+	return b.SubscribeOrderUpdatesCtx(context.Background(), dataCh)
+}
+
+// SubscribeOrderUpdatesCtx is the context-aware variant of SubscribeOrderUpdates.
+// It returns ctx.Err() once ctx is done.
+func (b *Bittrex) SubscribeOrderUpdatesCtx(ctx context.Context, dataCh chan<- OrderUpdate) error {
 	const timeout = 15 * time.Second
-	client := signalr.NewWebsocketClient()
+	client := b.client.newSignalrClient()
 
 	client.OnClientMethod = func(hub string, method string, messages []json.RawMessage) {
 
@@ -179,7 +234,7 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 			//fmt.Printf("AUTHEXPIRED\n")
 		default:
 			//handle unsupported type
-			fmt.Printf("unsupported message type: %s\n", method)
+			b.client.logger.Printf("unsupported message type: %s", method)
 			return
 		}
 
@@ -187,13 +242,13 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 
 			dbuf, err := base64.StdEncoding.DecodeString(strings.Trim(string(msg), `"`))
 			if err != nil {
-				fmt.Printf("DecodeString error: %s %s\n", err.Error(), string(msg))
+				b.client.logger.Printf("DecodeString error: %s %s", err.Error(), string(msg))
 				continue
 			}
 
 			r, err := zlib.NewReader(bytes.NewReader(append([]byte{120, 156}, dbuf...)))
 			if err != nil {
-				fmt.Printf("unzip error %s %s \n", err.Error(), string(msg))
+				b.client.logger.Printf("unzip error %s %s", err.Error(), string(msg))
 				continue
 			}
 			defer r.Close()
@@ -214,18 +269,18 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 			select {
 			case dataCh <- p:
 			default:
-				fmt.Printf("missed message: %v", p)
+				b.client.logger.Printf("missed message: %v", p)
 			}
 		}
 	}
 
 	client.OnMessageError = func(err error) {
-		fmt.Printf("ERROR OCCURRED: %s\n", err.Error())
+		b.client.logger.Printf("ERROR OCCURRED: %s", err.Error())
 	}
 
-	err := doAsyncTimeout(
+	err := doAsyncTimeout(ctx,
 		func() error {
-			return client.Connect("https", WS_BASE, []string{WS_HUB})
+			return client.Connect("https", b.client.wsHost, []string{b.client.wsHub})
 		}, func(err error) {
 			if err == nil {
 				client.Close()
@@ -236,13 +291,14 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 	}
 
 	defer client.Close()
+	defer closeOnDone(ctx, client)()
 
-	err = b.Authentication(client)
+	err = b.authenticate(client)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.CallHub(WS_HUB, "Subscribe", []interface{}{"heartbeat", "order"})
+	_, err = client.CallHub(b.client.wsHub, "Subscribe", []interface{}{"heartbeat", "order"})
 	if err != nil {
 		return err
 	}
@@ -250,11 +306,15 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 	ticker := time.NewTicker(5 * time.Minute)
 
 	for {
-		<-ticker.C
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 
-		err := b.Authentication(client)
+		err := b.authenticate(client)
 		if err != nil {
-			fmt.Printf("authentication error: %s\n", err)
+			b.client.logger.Printf("authentication error: %s", err)
 			return err
 		}
 	}
@@ -264,13 +324,37 @@ func (b *Bittrex) SubscribeOrderUpdates(dataCh chan<- OrderUpdate) error {
 // Updates will be sent to dataCh.
 // To stop subscription, send to, or close 'stop'.
 func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- OrderBook, stop chan bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := b.SubscribeOrderbookUpdatesCtx(ctx, market, orderbook)
+	if err == context.Canceled {
+		return errors.New("StopChannel")
+	}
+	return err
+}
+
+// SubscribeOrderbookUpdatesCtx is the context-aware variant of SubscribeOrderbookUpdates.
+// The subscription stops and ctx.Err() is returned once ctx is done.
+func (b *Bittrex) SubscribeOrderbookUpdatesCtx(ctx context.Context, market string, orderbook chan<- OrderBook) error {
 	const timeout = 5 * time.Second
-	client := signalr.NewWebsocketClient()
+	market, err := b.cachedMarketSymbol(market)
+	if err != nil {
+		return err
+	}
+	client := b.client.newSignalrClient()
 
 	var updTime time.Time
 
 	client.OnClientMethod = func(hub string, method string, messages []json.RawMessage) {
-		if hub != WS_HUB {
+		if hub != b.client.wsHub {
 			return
 		}
 
@@ -278,19 +362,19 @@ func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- Orde
 		case HEARTBEAT, ORDERBOOK:
 			updTime = time.Now()
 		default:
-			fmt.Printf("unsupported message type: %s %v\n", method, messages)
+			b.client.logger.Printf("unsupported message type: %s %v", method, messages)
 		}
 
 		for _, msg := range messages {
 			dbuf, err := base64.StdEncoding.DecodeString(strings.Trim(string(msg), `"`))
 			if err != nil {
-				fmt.Printf("DecodeString error: %s %s\n", err.Error(), string(msg))
+				b.client.logger.Printf("DecodeString error: %s %s", err.Error(), string(msg))
 				continue
 			}
 
 			r, err := zlib.NewReader(bytes.NewReader(append([]byte{120, 156}, dbuf...)))
 			if err != nil {
-				fmt.Printf("unzip error %s %s \n", err.Error(), string(msg))
+				b.client.logger.Printf("unzip error %s %s", err.Error(), string(msg))
 				continue
 			}
 			defer r.Close()
@@ -311,25 +395,25 @@ func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- Orde
 
 			err = json.Unmarshal([]byte(out.String()), &p)
 			if err != nil {
-				fmt.Printf("orderbook Unmarshal err: %s %s\n", err.Error(), market)
+				b.client.logger.Printf("orderbook Unmarshal err: %s %s", err.Error(), market)
 			}
 
 			select {
 			case orderbook <- p:
 			default:
-				fmt.Printf("orderbook send err: %s %d  \n", market, len(orderbook))
+				b.client.logger.Printf("orderbook send err: %s %d", market, len(orderbook))
 			}
 
 		}
 	}
 
 	client.OnMessageError = func(err error) {
-		fmt.Printf("ERROR OCCURRED: %s\n", err.Error())
+		b.client.logger.Printf("ERROR OCCURRED: %s", err.Error())
 	}
 
-	err := doAsyncTimeout(
+	err = doAsyncTimeout(ctx,
 		func() error {
-			return client.Connect("https", WS_BASE, []string{WS_HUB})
+			return client.Connect("https", b.client.wsHost, []string{b.client.wsHub})
 		}, func(err error) {
 			if err == nil {
 				client.Close()
@@ -340,8 +424,9 @@ func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- Orde
 	}
 
 	defer client.Close()
+	defer closeOnDone(ctx, client)()
 
-	_, err = client.CallHub(WS_HUB, "Subscribe", []interface{}{"heartbeat", "orderbook_" + market + "_25"})
+	_, err = client.CallHub(b.client.wsHub, "Subscribe", []interface{}{"heartbeat", OrderBookChannel(market, 25)})
 	if err != nil {
 		return err
 	}
@@ -351,9 +436,13 @@ func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- Orde
 	for {
 		select {
 		case <-client.DisconnectedChannel:
+			// closeOnDone closes the client too, report the cancellation
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return errors.New("client.DisconnectedChannel")
-		case <-stop:
-			return errors.New("StopChannel")
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-tick.C:
 			if time.Now().Sub(updTime) > time.Minute {
 				return errors.New("orderook messages timeout")
@@ -365,8 +454,14 @@ func (b *Bittrex) SubscribeOrderbookUpdates(market string, orderbook chan<- Orde
 
 // SubscribeBalanceUpdates func
 func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
+	return b.SubscribeBalanceUpdatesCtx(context.Background(), dataCh)
+}
+
+// SubscribeBalanceUpdatesCtx is the context-aware variant of SubscribeBalanceUpdates.
+// It returns ctx.Err() once ctx is done.
+func (b *Bittrex) SubscribeBalanceUpdatesCtx(ctx context.Context, dataCh chan<- BalanceUpdate) error {
 	const timeout = 15 * time.Second
-	client := signalr.NewWebsocketClient()
+	client := b.client.newSignalrClient()
 
 	client.OnClientMethod = func(hub string, method string, messages []json.RawMessage) {
 
@@ -374,7 +469,7 @@ func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
 		case BALANCE:
 		default:
 			//handle unsupported type
-			fmt.Printf("unsupported message type: %s\n", method)
+			b.client.logger.Printf("unsupported message type: %s", method)
 			return
 		}
 
@@ -382,13 +477,13 @@ func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
 
 			dbuf, err := base64.StdEncoding.DecodeString(strings.Trim(string(msg), `"`))
 			if err != nil {
-				fmt.Printf("DecodeString error: %s %s\n", err.Error(), string(msg))
+				b.client.logger.Printf("DecodeString error: %s %s", err.Error(), string(msg))
 				continue
 			}
 
 			r, err := zlib.NewReader(bytes.NewReader(append([]byte{120, 156}, dbuf...)))
 			if err != nil {
-				fmt.Printf("unzip error %s %s \n", err.Error(), string(msg))
+				b.client.logger.Printf("unzip error %s %s", err.Error(), string(msg))
 				continue
 			}
 			defer r.Close()
@@ -406,17 +501,21 @@ func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
 				//fmt.Printf("unsupported message type: %v", p.Method)
 			}
 
-			dataCh <- p
+			select {
+			case dataCh <- p:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 
 	client.OnMessageError = func(err error) {
-		fmt.Printf("ERROR OCCURRED: %s\n", err.Error())
+		b.client.logger.Printf("ERROR OCCURRED: %s", err.Error())
 	}
 
-	err := doAsyncTimeout(
+	err := doAsyncTimeout(ctx,
 		func() error {
-			return client.Connect("https", WS_BASE, []string{WS_HUB})
+			return client.Connect("https", b.client.wsHost, []string{b.client.wsHub})
 		}, func(err error) {
 			if err == nil {
 				client.Close()
@@ -427,13 +526,14 @@ func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
 	}
 
 	defer client.Close()
+	defer closeOnDone(ctx, client)()
 
-	err = b.Authentication(client)
+	err = b.authenticate(client)
 	if err != nil {
 		return err
 	}
 
-	_, err = client.CallHub(WS_HUB, "Subscribe", []interface{}{"balance"})
+	_, err = client.CallHub(b.client.wsHub, "Subscribe", []interface{}{"balance"})
 	if err != nil {
 		return err
 	}
@@ -441,11 +541,15 @@ func (b *Bittrex) SubscribeBalanceUpdates(dataCh chan<- BalanceUpdate) error {
 	ticker := time.NewTicker(5 * time.Minute)
 
 	for {
-		<-ticker.C
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
 
-		err := b.Authentication(client)
+		err := b.authenticate(client)
 		if err != nil {
-			fmt.Printf("authentication error: %s\n", err)
+			b.client.logger.Printf("authentication error: %s", err)
 			return err
 		}
 	}
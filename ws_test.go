@@ -1,9 +1,85 @@
 package bittrex
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mountalpha/basecamp-bittrex-connector/bittrextest"
 )
 
+func newTestWsHub(t *testing.T) (*bittrextest.Hub, *Bittrex) {
+	hub := bittrextest.NewHub("key", "secret")
+	t.Cleanup(hub.Close)
+	return hub, New("key", "secret", WithWebsocket(hub.Host(), WS_HUB), WithWebsocketTLSConfig(hub.TLSConfig()))
+}
+
 func TestBittrexSubscribeOrderBook(t *testing.T) {
+	hub, b := newTestWsHub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan OrderBook, 1)
+	errs := make(chan error, 1)
+	go func() { errs <- b.SubscribeOrderbookUpdatesCtx(ctx, "ltc-btc", ch) }()
+
+	assert.Nil(t, hub.WaitSubscribed("orderbook_LTC-BTC_25", 5*time.Second))
+	assert.Nil(t, hub.PushOrderBook(bittrextest.OrderBookUpdate{
+		MarketSymbol: "LTC-BTC",
+		Depth:        25,
+		Sequence:     1,
+		BidDeltas:    []bittrextest.OrderBookEntry{{Rate: d("0.004"), Quantity: d("1")}},
+	}))
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order book received")
+	}
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled))
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not stop")
+	}
+}
+
+func TestBittrexSubscribeOrderUpdates(t *testing.T) {
+	hub, b := newTestWsHub(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := make(chan OrderUpdate, 1)
+	go b.SubscribeOrderUpdatesCtx(ctx, ch)
 
+	assert.Nil(t, hub.WaitSubscribed("order", 5*time.Second))
+	limit := d("0.004")
+	assert.Nil(t, hub.PushOrder(bittrextest.OrderUpdate{
+		AccountID: "account",
+		Sequence:  7,
+		Delta: bittrextest.Order{
+			ID:           "order",
+			MarketSymbol: "LTC-BTC",
+			Direction:    "BUY",
+			Type:         "LIMIT",
+			Limit:        &limit,
+			FillQuantity: d("0.5"),
+			Status:       "OPEN",
+			CreatedAt:    time.Now(),
+		},
+	}))
+	select {
+	case u := <-ch:
+		assert.Equal(t, 7, u.Sequence)
+		assert.Equal(t, "order", u.Delta.ID)
+		assert.Equal(t, "0.004", u.Delta.Limit)
+		assert.Equal(t, "0.5", u.Delta.FillQuantity)
+		assert.Equal(t, "OPEN", u.Delta.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order update received")
+	}
 }